@@ -0,0 +1,257 @@
+// Package config holds JSON-loadable server settings, with sane defaults
+// matching the values that used to be hard-coded across the game and
+// network packages.
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// CannonConfig tunes the primary weapon.
+type CannonConfig struct {
+	CooldownMs  int     `json:"cooldownMs"`
+	Damage      int     `json:"damage"`
+	BulletSpeed float64 `json:"bulletSpeed"`
+}
+
+// MortarConfig tunes the secondary, delayed-impact weapon.
+type MortarConfig struct {
+	CooldownMs     int     `json:"cooldownMs"`
+	Ammo           int     `json:"ammo"`
+	MaxAmmo        int     `json:"maxAmmo"`
+	RechargeMs     int     `json:"rechargeMs"`
+	ImpactDelayMs  int     `json:"impactDelayMs"`
+	ImpactDistance float64 `json:"impactDistance"`
+	ImpactRadius   float64 `json:"impactRadius"`
+	Damage         int     `json:"damage"`
+}
+
+// WeaponConfig groups the tunables for every weapon.
+type WeaponConfig struct {
+	Cannon CannonConfig `json:"cannon"`
+	Mortar MortarConfig `json:"mortar"`
+}
+
+// Config holds every tunable server setting. Zero-valued fields are
+// treated as "not set" when merging overrides onto a base Config.
+type Config struct {
+	TickMs             int          `json:"tickMs"`
+	MapWidth           float64      `json:"mapWidth"`
+	MapHeight          float64      `json:"mapHeight"`
+	MaxPlayersPerLobby int          `json:"maxPlayersPerLobby"`
+	MaxLobbies         int          `json:"maxLobbies"`
+	LobbyTimeoutSec    int          `json:"lobbyTimeoutSec"`
+	IdleThresholdSec   int          `json:"idleThresholdSec"`
+	ObstacleCount      int          `json:"obstacleCount"`
+	ScannerRadius      float64      `json:"scannerRadius"`
+	TanksPerPlayer     int          `json:"tanksPerPlayer"`
+	Timescale          float64      `json:"timescale"`
+	Mode               string       `json:"mode"`
+	Weapons            WeaponConfig `json:"weapons"`
+}
+
+// validModes lists the game modes a lobby can be configured with.
+var validModes = map[string]bool{
+	"deathmatch": true,
+	"ctf":        true,
+}
+
+// Delta returns the simulation step, in seconds, Game.Update should be
+// advanced by on every tick: the tick's wall-clock duration scaled by
+// Timescale, so a lobby can run the simulation in slow motion or fast
+// forward without changing how often ticks fire.
+func (c Config) Delta() float64 {
+	return (float64(c.TickMs) / 1000) * c.Timescale
+}
+
+// Default returns the settings this server has always shipped with,
+// matching the constants that used to be hard-coded in game/network.
+func Default() Config {
+	return Config{
+		TickMs:             50,
+		MapWidth:           1200,
+		MapHeight:          800,
+		MaxPlayersPerLobby: 2,
+		MaxLobbies:         5,
+		LobbyTimeoutSec:    30 * 60,
+		IdleThresholdSec:   60,
+		ObstacleCount:      8,
+		ScannerRadius:      2000,
+		TanksPerPlayer:     1,
+		Timescale:          1.0,
+		Mode:               "deathmatch",
+		Weapons: WeaponConfig{
+			Cannon: CannonConfig{
+				CooldownMs:  500,
+				Damage:      100,
+				BulletSpeed: 600,
+			},
+			Mortar: MortarConfig{
+				CooldownMs:     3000,
+				Ammo:           3,
+				MaxAmmo:        3,
+				RechargeMs:     10000,
+				ImpactDelayMs:  3000,
+				ImpactDistance: 300,
+				ImpactRadius:   50,
+				Damage:         100,
+			},
+		},
+	}
+}
+
+// LoadConfig reads a JSON file and merges it onto Default(), so any field
+// the file omits keeps its hard-coded default value.
+func LoadConfig(path string) (Config, error) {
+	cfg := Default()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+
+	var overrides Config
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return cfg, err
+	}
+
+	return cfg.WithOverrides(overrides), nil
+}
+
+// WithOverrides returns a copy of c with every non-zero field of o applied
+// on top of it. Used both for loading a config file and for per-lobby
+// overrides sent in a create-lobby request.
+func (c Config) WithOverrides(o Config) Config {
+	if o.TickMs != 0 {
+		c.TickMs = o.TickMs
+	}
+	if o.MapWidth != 0 {
+		c.MapWidth = o.MapWidth
+	}
+	if o.MapHeight != 0 {
+		c.MapHeight = o.MapHeight
+	}
+	if o.MaxPlayersPerLobby != 0 {
+		c.MaxPlayersPerLobby = o.MaxPlayersPerLobby
+	}
+	if o.MaxLobbies != 0 {
+		c.MaxLobbies = o.MaxLobbies
+	}
+	if o.LobbyTimeoutSec != 0 {
+		c.LobbyTimeoutSec = o.LobbyTimeoutSec
+	}
+	if o.IdleThresholdSec != 0 {
+		c.IdleThresholdSec = o.IdleThresholdSec
+	}
+	if o.ObstacleCount != 0 {
+		c.ObstacleCount = o.ObstacleCount
+	}
+	if o.ScannerRadius != 0 {
+		c.ScannerRadius = o.ScannerRadius
+	}
+	if o.TanksPerPlayer != 0 {
+		c.TanksPerPlayer = o.TanksPerPlayer
+	}
+	if o.Timescale != 0 {
+		c.Timescale = o.Timescale
+	}
+	if o.Mode != "" {
+		c.Mode = o.Mode
+	}
+
+	cannon := &o.Weapons.Cannon
+	if cannon.CooldownMs != 0 {
+		c.Weapons.Cannon.CooldownMs = cannon.CooldownMs
+	}
+	if cannon.Damage != 0 {
+		c.Weapons.Cannon.Damage = cannon.Damage
+	}
+	if cannon.BulletSpeed != 0 {
+		c.Weapons.Cannon.BulletSpeed = cannon.BulletSpeed
+	}
+
+	mortar := &o.Weapons.Mortar
+	if mortar.CooldownMs != 0 {
+		c.Weapons.Mortar.CooldownMs = mortar.CooldownMs
+	}
+	if mortar.Ammo != 0 {
+		c.Weapons.Mortar.Ammo = mortar.Ammo
+	}
+	if mortar.MaxAmmo != 0 {
+		c.Weapons.Mortar.MaxAmmo = mortar.MaxAmmo
+	}
+	if mortar.RechargeMs != 0 {
+		c.Weapons.Mortar.RechargeMs = mortar.RechargeMs
+	}
+	if mortar.ImpactDelayMs != 0 {
+		c.Weapons.Mortar.ImpactDelayMs = mortar.ImpactDelayMs
+	}
+	if mortar.ImpactDistance != 0 {
+		c.Weapons.Mortar.ImpactDistance = mortar.ImpactDistance
+	}
+	if mortar.ImpactRadius != 0 {
+		c.Weapons.Mortar.ImpactRadius = mortar.ImpactRadius
+	}
+	if mortar.Damage != 0 {
+		c.Weapons.Mortar.Damage = mortar.Damage
+	}
+
+	return c
+}
+
+// Validate checks that every setting is within a safe range, so a
+// malformed or hostile per-lobby override can't wedge the server (e.g. a
+// zero tick rate or negative map size).
+func (c Config) Validate() error {
+	switch {
+	case c.TickMs < 10 || c.TickMs > 1000:
+		return fmt.Errorf("tickMs must be between 10 and 1000, got %d", c.TickMs)
+	case c.MapWidth < 200 || c.MapWidth > 10000:
+		return fmt.Errorf("mapWidth must be between 200 and 10000, got %v", c.MapWidth)
+	case c.MapHeight < 200 || c.MapHeight > 10000:
+		return fmt.Errorf("mapHeight must be between 200 and 10000, got %v", c.MapHeight)
+	case c.MaxPlayersPerLobby < 1 || c.MaxPlayersPerLobby > 16:
+		return fmt.Errorf("maxPlayersPerLobby must be between 1 and 16, got %d", c.MaxPlayersPerLobby)
+	case c.MaxLobbies < 1 || c.MaxLobbies > 1000:
+		return fmt.Errorf("maxLobbies must be between 1 and 1000, got %d", c.MaxLobbies)
+	case c.LobbyTimeoutSec < 30 || c.LobbyTimeoutSec > 24*60*60:
+		return fmt.Errorf("lobbyTimeoutSec must be between 30 and 86400, got %d", c.LobbyTimeoutSec)
+	case c.IdleThresholdSec < 10 || c.IdleThresholdSec > 600:
+		return fmt.Errorf("idleThresholdSec must be between 10 and 600, got %d", c.IdleThresholdSec)
+	case c.ObstacleCount < 0 || c.ObstacleCount > 100:
+		return fmt.Errorf("obstacleCount must be between 0 and 100, got %d", c.ObstacleCount)
+	case c.ScannerRadius < 0 || c.ScannerRadius > 20000:
+		return fmt.Errorf("scannerRadius must be between 0 and 20000, got %v", c.ScannerRadius)
+	case c.TanksPerPlayer < 1 || c.TanksPerPlayer > 8:
+		return fmt.Errorf("tanksPerPlayer must be between 1 and 8, got %d", c.TanksPerPlayer)
+	case c.Timescale <= 0 || c.Timescale > 10:
+		return fmt.Errorf("timescale must be between 0 (exclusive) and 10, got %v", c.Timescale)
+	case !validModes[c.Mode]:
+		return fmt.Errorf("mode must be one of deathmatch, ctf, got %q", c.Mode)
+	case c.Weapons.Cannon.CooldownMs < 50 || c.Weapons.Cannon.CooldownMs > 10000:
+		return fmt.Errorf("weapons.cannon.cooldownMs must be between 50 and 10000, got %d", c.Weapons.Cannon.CooldownMs)
+	case c.Weapons.Cannon.Damage < 0 || c.Weapons.Cannon.Damage > 1000:
+		return fmt.Errorf("weapons.cannon.damage must be between 0 and 1000, got %d", c.Weapons.Cannon.Damage)
+	case c.Weapons.Cannon.BulletSpeed < 0 || c.Weapons.Cannon.BulletSpeed > 5000:
+		return fmt.Errorf("weapons.cannon.bulletSpeed must be between 0 and 5000, got %v", c.Weapons.Cannon.BulletSpeed)
+	case c.Weapons.Mortar.CooldownMs < 0 || c.Weapons.Mortar.CooldownMs > 60000:
+		return fmt.Errorf("weapons.mortar.cooldownMs must be between 0 and 60000, got %d", c.Weapons.Mortar.CooldownMs)
+	case c.Weapons.Mortar.MaxAmmo < 0 || c.Weapons.Mortar.MaxAmmo > 100:
+		return fmt.Errorf("weapons.mortar.maxAmmo must be between 0 and 100, got %d", c.Weapons.Mortar.MaxAmmo)
+	case c.Weapons.Mortar.Ammo < 0 || c.Weapons.Mortar.Ammo > c.Weapons.Mortar.MaxAmmo:
+		return fmt.Errorf("weapons.mortar.ammo must be between 0 and maxAmmo (%d), got %d", c.Weapons.Mortar.MaxAmmo, c.Weapons.Mortar.Ammo)
+	case c.Weapons.Mortar.RechargeMs < 0 || c.Weapons.Mortar.RechargeMs > 120000:
+		return fmt.Errorf("weapons.mortar.rechargeMs must be between 0 and 120000, got %d", c.Weapons.Mortar.RechargeMs)
+	case c.Weapons.Mortar.ImpactDelayMs < 0 || c.Weapons.Mortar.ImpactDelayMs > 10000:
+		return fmt.Errorf("weapons.mortar.impactDelayMs must be between 0 and 10000, got %d", c.Weapons.Mortar.ImpactDelayMs)
+	case c.Weapons.Mortar.ImpactDistance < 0 || c.Weapons.Mortar.ImpactDistance > 5000:
+		return fmt.Errorf("weapons.mortar.impactDistance must be between 0 and 5000, got %v", c.Weapons.Mortar.ImpactDistance)
+	case c.Weapons.Mortar.ImpactRadius < 0 || c.Weapons.Mortar.ImpactRadius > 2000:
+		return fmt.Errorf("weapons.mortar.impactRadius must be between 0 and 2000, got %v", c.Weapons.Mortar.ImpactRadius)
+	case c.Weapons.Mortar.Damage < 0 || c.Weapons.Mortar.Damage > 1000:
+		return fmt.Errorf("weapons.mortar.damage must be between 0 and 1000, got %d", c.Weapons.Mortar.Damage)
+	}
+	return nil
+}
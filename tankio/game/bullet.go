@@ -30,10 +30,12 @@ type Bullet struct {
 	HasImpacted  bool      `json:"-"`
 }
 
-// Update moves the bullet and returns false if it should be removed
-func (b *Bullet) Update(dt float64) bool {
+// Update moves the bullet and returns false if it should be removed. now is
+// the game's current clock time, so playback can drive this off a replayed
+// ManualClock instead of the real wall clock.
+func (b *Bullet) Update(dt float64, now time.Time) bool {
 	// Check if bullet is too old
-	if time.Since(b.CreatedAt) > b.MaxAge {
+	if now.Sub(b.CreatedAt) > b.MaxAge {
 		return false
 	}
 
@@ -44,7 +46,7 @@ func (b *Bullet) Update(dt float64) bool {
 	case BulletMortar:
 		// Mortar shells don't move - they have a fixed impact position
 		// The visual arc is handled client-side
-		if time.Now().After(b.ImpactTime) && !b.HasImpacted {
+		if now.After(b.ImpactTime) && !b.HasImpacted {
 			b.HasImpacted = true
 			b.Position = b.ImpactPos // Snap to impact position
 		}
@@ -73,13 +75,16 @@ func (b *Bullet) IsActive() bool {
 	return false
 }
 
-// GetFlightProgress returns 0-1 for mortar shells (for client-side arc animation)
-func (b *Bullet) GetFlightProgress() float64 {
+// GetFlightProgress returns 0-1 for mortar shells (for client-side arc
+// animation). now is the game's current clock time, the same one Update
+// is driven by, so playback computes progress against the replayed
+// ManualClock instead of the real wall clock.
+func (b *Bullet) GetFlightProgress(now time.Time) float64 {
 	if b.Type != BulletMortar {
 		return 1.0
 	}
 	totalFlight := b.ImpactTime.Sub(b.CreatedAt)
-	elapsed := time.Since(b.CreatedAt)
+	elapsed := now.Sub(b.CreatedAt)
 	progress := float64(elapsed) / float64(totalFlight)
 	if progress > 1.0 {
 		return 1.0
@@ -98,15 +103,16 @@ type BulletState struct {
 	ImpactRadius   float64    `json:"impactRadius,omitempty"`
 }
 
-// ToState converts a bullet to its client-visible state
-func (b *Bullet) ToState() BulletState {
+// ToState converts a bullet to its client-visible state. now is the
+// game's current clock time, passed through to GetFlightProgress.
+func (b *Bullet) ToState(now time.Time) BulletState {
 	return BulletState{
 		ID:             b.ID,
 		OwnerID:        b.OwnerID,
 		Position:       b.Position,
 		Type:           b.Type,
 		ImpactPos:      b.ImpactPos,
-		FlightProgress: b.GetFlightProgress(),
+		FlightProgress: b.GetFlightProgress(now),
 		ImpactRadius:   b.ImpactRadius,
 	}
 }
@@ -0,0 +1,49 @@
+package game
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock abstracts wall-clock time so a match can be replayed deterministically
+// against recorded inputs instead of real time. Live lobbies use RealClock;
+// playback lobbies use a ManualClock advanced one tick at a time.
+type Clock interface {
+	Now() time.Time
+}
+
+// realClock is the Clock every live match runs on.
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+// RealClock returns the Clock backed by the actual wall clock.
+func RealClock() Clock { return realClock{} }
+
+// ManualClock is a Clock that only advances when told to, so a playback
+// lobby can step through a recorded match tick-for-tick without drifting
+// from the original's timing decisions (cooldowns, impact times, bullet
+// ages).
+type ManualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewManualClock creates a ManualClock starting at start.
+func NewManualClock(start time.Time) *ManualClock {
+	return &ManualClock{now: start}
+}
+
+// Now returns the clock's current time.
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d.
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
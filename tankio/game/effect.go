@@ -0,0 +1,198 @@
+package game
+
+import "fmt"
+
+// Effect kinds, shared with the network layer's wire message types so a
+// client payload can be routed to its concrete Effect by string alone.
+const (
+	EffectMove          = "input"
+	EffectFire          = "fire"
+	EffectSwitchWeapon  = "switch_weapon"
+	EffectSelectTank    = "select_tank"
+	EffectConfigureTank = "configure_tank"
+)
+
+// Effect is one action a player submits to be applied to the simulation.
+// Submitting an effect (via Game.Enqueue) never mutates game state itself;
+// only Game.Update calls Exec, from its own single-threaded tick, so
+// effects enqueued from a websocket read-pump goroutine can never race with
+// the simulation. Adding a new ability is a new Effect implementation plus
+// one EffectRegistry entry -- nothing else needs to change.
+type Effect interface {
+	// Kind identifies this effect's wire type; also its EffectRegistry key.
+	Kind() string
+	// Exec applies the effect to playerID's tank. Caller must hold g.mu.
+	Exec(g *Game, playerID string) error
+}
+
+// EffectRegistry maps a wire kind to a constructor for a fresh zero-value
+// Effect, so a caller (Client.handleMessage unmarshaling a client message,
+// or replay decoding a recorded frame) can turn a JSON payload into the
+// right concrete Effect without a hardcoded type switch.
+var EffectRegistry = map[string]func() Effect{
+	EffectMove:          func() Effect { return &MoveEffect{} },
+	EffectFire:          func() Effect { return &FireEffect{} },
+	EffectSwitchWeapon:  func() Effect { return &SwitchWeaponEffect{} },
+	EffectSelectTank:    func() Effect { return &SelectTankEffect{} },
+	EffectConfigureTank: func() Effect { return &ConfigureTankEffect{} },
+}
+
+// unknownPlayerErr is returned by Exec when playerID no longer has a tank
+// (e.g. it disconnected between submitting the effect and the next tick).
+func unknownPlayerErr(playerID string) error {
+	return fmt.Errorf("game: unknown player %q", playerID)
+}
+
+// unknownTankErr is returned by Exec when a TankID doesn't name one of
+// playerID's own tanks.
+func unknownTankErr(playerID, tankID string) error {
+	return fmt.Errorf("game: player %q has no tank %q", playerID, tankID)
+}
+
+// resolveTank looks up the tank an effect from playerID should apply to:
+// the one named by tankID, or playerID's currently selected tank if
+// tankID is empty, for clients that only ever drive one tank at a time.
+// Caller must hold g.mu.
+func (g *Game) resolveTank(playerID, tankID string) (*Tank, error) {
+	p, ok := g.players[playerID]
+	if !ok {
+		return nil, unknownPlayerErr(playerID)
+	}
+	if tankID == "" {
+		tankID = p.Selected
+	}
+	t, ok := p.Tank(tankID)
+	if !ok {
+		return nil, unknownTankErr(playerID, tankID)
+	}
+	return t, nil
+}
+
+// MoveEffect updates a tank's movement/aim/fire-intent input state.
+// TankID addresses a specific tank in the player's fleet; left empty, it
+// targets whichever tank MsgTypeSelectTank last focused.
+type MoveEffect struct {
+	TankID string `json:"tankId,omitempty"`
+	InputState
+}
+
+func (e *MoveEffect) Kind() string { return EffectMove }
+
+func (e *MoveEffect) Exec(g *Game, playerID string) error {
+	tank, err := g.resolveTank(playerID, e.TankID)
+	if err != nil {
+		return err
+	}
+	tank.Input = e.InputState
+	return nil
+}
+
+// IsActivity reports whether this move represents meaningful player intent
+// (a key held or firing), as opposed to an idle resting frame. Client uses
+// this to decide whether to reset a player's idle-kick clock.
+func (e *MoveEffect) IsActivity() bool {
+	return e.Up || e.Down || e.Left || e.Right || e.Firing
+}
+
+// FireEffect fires the selected tank's currently active weapon. The
+// resulting bullet is attributed to the player, not the tank, so fleet
+// fire is scored the same way regardless of which tank pulled the trigger.
+type FireEffect struct{}
+
+func (e *FireEffect) Kind() string { return EffectFire }
+
+func (e *FireEffect) Exec(g *Game, playerID string) error {
+	tank, err := g.resolveTank(playerID, "")
+	if err != nil {
+		return err
+	}
+	if b := tank.Fire(playerID); b != nil {
+		g.bullets = append(g.bullets, b)
+	}
+	return nil
+}
+
+// SwitchWeaponEffect changes a tank's active weapon. TankID addresses a
+// specific tank in the player's fleet; left empty, it targets the
+// currently selected tank.
+type SwitchWeaponEffect struct {
+	TankID string     `json:"tankId,omitempty"`
+	Weapon WeaponType `json:"weapon"`
+}
+
+func (e *SwitchWeaponEffect) Kind() string { return EffectSwitchWeapon }
+
+func (e *SwitchWeaponEffect) Exec(g *Game, playerID string) error {
+	tank, err := g.resolveTank(playerID, e.TankID)
+	if err != nil {
+		return err
+	}
+	tank.SwitchWeapon(e.Weapon)
+	return nil
+}
+
+// SelectTankEffect focuses a player's movement and aim input on one tank
+// in its fleet, leaving the rest holding position until selected in turn.
+type SelectTankEffect struct {
+	TankID string `json:"tankId"`
+}
+
+func (e *SelectTankEffect) Kind() string { return EffectSelectTank }
+
+func (e *SelectTankEffect) Exec(g *Game, playerID string) error {
+	p, ok := g.players[playerID]
+	if !ok {
+		return unknownPlayerErr(playerID)
+	}
+	if !p.Select(e.TankID) {
+		return unknownTankErr(playerID, e.TankID)
+	}
+	return nil
+}
+
+// speedHealthTradeRate is the health cost of each pixel/second of MaxSpeed
+// a player buys above the default via ConfigureTankEffect.
+const speedHealthTradeRate = 0.4
+
+// maxSpeedBonus caps how much MaxSpeed a player can buy, so a fully
+// traded-out tank still keeps a meaningful health pool.
+const maxSpeedBonus = 200.0
+
+// ConfigureTankEffect lets a player trade health for a faster tank before
+// the match starts: MaxSpeed above the default is deducted from MaxHealth
+// at speedHealthTradeRate HP per unit, like a class-select screen. It's
+// rejected once the match is underway so a tank already in a fight can't
+// reroll its stats mid-fire.
+type ConfigureTankEffect struct {
+	TankID   string  `json:"tankId,omitempty"`
+	MaxSpeed float64 `json:"maxSpeed"`
+}
+
+func (e *ConfigureTankEffect) Kind() string { return EffectConfigureTank }
+
+func (e *ConfigureTankEffect) Exec(g *Game, playerID string) error {
+	if g.state != Waiting {
+		return fmt.Errorf("game: tank stats can only be configured before the match starts")
+	}
+
+	tank, err := g.resolveTank(playerID, e.TankID)
+	if err != nil {
+		return err
+	}
+
+	bonus := e.MaxSpeed - DefaultTankStats().MaxSpeed
+	if bonus < 0 {
+		bonus = 0
+	}
+	if bonus > maxSpeedBonus {
+		bonus = maxSpeedBonus
+	}
+
+	stats := DefaultTankStats()
+	stats.MaxSpeed += bonus
+	tank.Stats = stats
+
+	tank.MaxHealth = 100 - int(bonus*speedHealthTradeRate)
+	tank.Health = tank.MaxHealth
+	return nil
+}
@@ -1,107 +1,457 @@
 package game
+
 import (
-    "math"
-    "github.com/hajimehoshi/ebiten/v2"
-)
+	"fmt"
+	"math"
+	"sync"
 
-var invSqrt2 = 1 / math.Sqrt(2) 
+	"tankio/config"
+)
 
 const MAP_WIDTH int = 20
 const MAP_HEIGHT int = 20
+
+// GameState represents the current phase of a match
 type GameState string
+
 const (
 	Waiting  GameState = "waiting"
 	Playing  GameState = "playing"
 	GameOver GameState = "gameover"
 )
-// Game holds all your game state
+
+// pendingEffect pairs an Effect with the player it should be applied to.
+type pendingEffect struct {
+	playerID string
+	effect   Effect
+}
+
+// Game holds authoritative server-side state for a match: tanks, bullets,
+// and the map they're fought on. A Lobby drives Update and wires
+// BroadcastFn to push state out over the network.
 type Game struct {
-	players []Player 
-	blocks []Block 
-	bullets []Bullet
-	state GameState
-	mapWidth int
-	mapHeight int
-}
-// New creates a new game instance
+	mu sync.RWMutex
+
+	cfg         config.Config
+	clock       Clock
+	ids         *IDGenerator
+	players     map[string]*Player
+	bullets     []*Bullet
+	splosions   []*Splosion
+	state       GameState
+	mapBounds   Rectangle
+	spawnPoints []Vector2
+	nextSpawn   int
+	obstacles   []Obstacle
+
+	pendingMu sync.Mutex
+	pending   []pendingEffect
+
+	// BroadcastFn, if set, pushes a message to clients; the lobby sets it to
+	// its own BroadcastMessage. Game itself never calls it or touches the
+	// network.
+	BroadcastFn func(msg interface{})
+}
+
+// New creates a game instance for local (non-networked) play.
 func New() *Game {
-    return &Game{
-        players:    []Player{},
-        blocks:     []Block{},
-        bullets:    []Bullet{},
-        state:      Waiting,
-        mapWidth:  MAP_WIDTH,
-        mapHeight: MAP_HEIGHT,
-    }
+	return NewGame(config.Default())
+}
+
+// NewGame creates a new authoritative game using cfg's map and weapon
+// settings, running on the real wall clock with a fresh ID generator.
+func NewGame(cfg config.Config) *Game {
+	return NewGameDeterministic(cfg, RealClock(), NewIDGenerator(0), 0)
+}
+
+// NewGameDeterministic creates a new authoritative game using cfg's map and
+// weapon settings, driven by clock and ids instead of the real wall clock
+// and a fresh counter, with its arena obstacles generated from seed. A
+// playback lobby uses this to re-simulate a recorded match: a ManualClock
+// stepped at the recorded tick rate, an IDGenerator seeded from the
+// recording, and the same obstacle seed reproduce the original match's
+// timing, bullet IDs, and arena layout exactly.
+func NewGameDeterministic(cfg config.Config, clock Clock, ids *IDGenerator, seed int64) *Game {
+	m := MapConfig{Width: cfg.MapWidth, Height: cfg.MapHeight}
+	bounds := m.GetBounds()
+	spawnPoints := m.GetSpawnPoints(cfg.MaxPlayersPerLobby)
+	return &Game{
+		cfg:         cfg,
+		clock:       clock,
+		ids:         ids,
+		players:     make(map[string]*Player),
+		bullets:     []*Bullet{},
+		splosions:   []*Splosion{},
+		state:       Waiting,
+		mapBounds:   bounds,
+		spawnPoints: spawnPoints,
+		obstacles:   GenerateObstacles(bounds, spawnPoints, seed, cfg.ObstacleCount),
+	}
+}
+
+// Obstacles returns the arena's static obstacles. They're generated once at
+// construction and never change, so callers don't need to hold g.mu.
+func (g *Game) Obstacles() []Obstacle {
+	return g.obstacles
+}
+
+// State returns the current game state.
+func (g *Game) State() GameState {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.state
+}
+
+// SetState forces the game into a given state.
+func (g *Game) SetState(s GameState) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.state = s
+}
+
+// AddPlayer spawns a player's fleet of cfg.TanksPerPlayer tanks, with
+// distinct IDs derived from id, and returns the Player grouping them.
+func (g *Game) AddPlayer(id string) *Player {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	base := g.spawnPoints[g.nextSpawn%len(g.spawnPoints)]
+	g.nextSpawn++
+
+	tanks := make([]*Tank, g.cfg.TanksPerPlayer)
+	halfSize := TankSize / 2
+	for i := range tanks {
+		// Fan each tank out from the spawn point vertically, alternating
+		// above and below it, so a fleet doesn't spawn stacked on top of
+		// itself without running off the map's left/right spawn edges
+		// the way offsetting along X would for a large fleet. Still
+		// clamp to mapBounds, since a large enough fleet can fan past
+		// the top/bottom edge instead.
+		offset := float64((i+1)/2) * (TankSize + 10)
+		if i%2 == 1 {
+			offset = -offset
+		}
+		pos := base.Add(Vector2{Y: offset})
+		pos.X = math.Max(g.mapBounds.X+halfSize, math.Min(pos.X, g.mapBounds.X+g.mapBounds.Width-halfSize))
+		pos.Y = math.Max(g.mapBounds.Y+halfSize, math.Min(pos.Y, g.mapBounds.Y+g.mapBounds.Height-halfSize))
+
+		// A fanned-out tank can still land inside an obstacle even though
+		// the base spawn point itself is kept clear (see
+		// obstacleSpawnClearance): slide it out the same way Tank.Update
+		// does for a moving tank, rather than leaving it wedged there.
+		hitbox := Circle{Center: pos, Radius: halfSize}
+		for _, o := range g.obstacles {
+			if o.BlocksTanks && o.IntersectsCircle(hitbox) {
+				hitbox.Center = o.PushOut(hitbox)
+			}
+		}
+		pos = hitbox.Center
+
+		tankID := fmt.Sprintf("%s-%d", id, i)
+		tanks[i] = NewTank(tankID, pos, g.cfg.Weapons, g.clock, g.ids, g.cfg.ScannerRadius)
+	}
+
+	player := NewPlayer(id, tanks)
+	g.players[id] = player
+
+	if g.state == Waiting && len(g.players) >= 2 {
+		g.state = Playing
+	}
+	return player
 }
 
-// Update is called every tick (60 times per second by default)
-// This is where you handle input and update game logic
-func (g *Game) Update() error {
-	switch g.state{
-	case Waiting:
-		if len(g.players) >= 2{
-			g.SetState(Playing)
+// KillPlayer zeroes every tank in a player's fleet without removing them
+// from the game, e.g. when evicting an idle player whose slot should stay
+// reserved for the disconnect grace period to handle.
+func (g *Game) KillPlayer(id string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if p, ok := g.players[id]; ok {
+		for _, t := range p.Tanks {
+			t.TakeDamage(t.Health)
 		}
-	case Playing:
-        	g.MovePlayers()
-	case GameOver:
+	}
+}
+
+// RemovePlayer removes a player's tank from the game.
+func (g *Game) RemovePlayer(id string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
 
+	delete(g.players, id)
+	if len(g.players) == 0 {
+		g.state = Waiting
 	}
-	// TODO: Handle input and update positions here
-	// Example:
-	// if ebiten.IsKeyPressed(ebiten.KeyW) {
-	//     g.playerY -= 2
-	// }
+}
 
-	return nil
+// GetPlayer returns a player and its fleet, if present.
+func (g *Game) GetPlayer(id string) (*Player, bool) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	p, ok := g.players[id]
+	return p, ok
 }
 
-// Draw is called every frame to render the screen
-func (g *Game) Draw(screen *ebiten.Image) {
-	// TODO: Draw your game here
-	// Example:
-	// ebitenutil.DrawRect(screen, g.playerX, g.playerY, 40, 40, color.White)
+// HasPlayer reports whether a player is currently part of the game.
+func (g *Game) HasPlayer(id string) bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	_, ok := g.players[id]
+	return ok
 }
 
-// Layout returns the logical screen size
-// Ebitengine will scale this to fit the window
-func (g *Game) Layout(outsideWidth, outsideHeight int) (int, int) {
-	return 800, 600
+// Enqueue submits an effect to be applied to playerID's tank on the next
+// call to Update, rather than mutating game state immediately. This is
+// what makes it safe to call from a websocket read-pump goroutine: Update
+// is the only place an Effect's Exec ever runs.
+func (g *Game) Enqueue(playerID string, e Effect) {
+	g.pendingMu.Lock()
+	g.pending = append(g.pending, pendingEffect{playerID: playerID, effect: e})
+	g.pendingMu.Unlock()
 }
-func (g *Game) SetState(s GameState){
-	g.state = s
+
+// applyPending runs every effect enqueued since the last tick. Caller must
+// hold g.mu.
+func (g *Game) applyPending() {
+	g.pendingMu.Lock()
+	pending := g.pending
+	g.pending = nil
+	g.pendingMu.Unlock()
+
+	for _, pe := range pending {
+		// A player who disconnected between submitting and this tick has no
+		// tank left to apply the effect to; drop it.
+		pe.effect.Exec(g, pe.playerID)
+	}
+}
+
+// Update advances the simulation by dt seconds.
+func (g *Game) Update(dt float64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.applyPending()
+
+	if g.state != Playing {
+		return
+	}
+
+	for _, p := range g.players {
+		for _, t := range p.Tanks {
+			t.Update(p, dt, g.mapBounds, g.obstacles)
+		}
+	}
+
+	now := g.clock.Now()
+	alive := g.bullets[:0]
+	for _, b := range g.bullets {
+		wasImpacted := b.HasImpacted
+		if !b.Update(dt, now) {
+			continue
+		}
+		if b.Type == BulletNormal && g.blockedByObstacle(b.GetHitbox()) {
+			continue
+		}
+		if b.Type == BulletMortar && b.HasImpacted && !wasImpacted {
+			// The shell reached its target this tick; it hands off to a
+			// Splosion for damage and doesn't linger as a bullet itself.
+			g.spawnSplosion(b)
+			continue
+		}
+		alive = append(alive, b)
+	}
+	g.bullets = alive
+
+	g.resolveHits()
+	g.updateSplosions(dt)
+}
+
+// blockedByObstacle reports whether hitbox overlaps an obstacle that blocks
+// bullets. Caller must hold g.mu.
+func (g *Game) blockedByObstacle(hitbox Circle) bool {
+	for _, o := range g.obstacles {
+		if o.BlocksBullets && o.IntersectsCircle(hitbox) {
+			return true
+		}
+	}
+	return false
 }
-func (g *Game) MovePlayers(){
-    for i := range g.players{
-        p := &g.players[i]  
-        
-        dx := float64((p.keys & 8) >> 3) - float64((p.keys & 2) >> 1)  // D - A
-        dy := float64((p.keys & 4) >> 2) - float64(p.keys & 1)         // S - W
-        
-        if dx != 0 && dy != 0 {
-            dx *= invSqrt2
-            dy *= invSqrt2
-        }
-        
-        nx, ny := p.x + dx, p.y + dy
-        if g.inbounds(nx, ny){
-            p.x, p.y = nx, ny
-        }
-    }
+
+// resolveHits applies bullet damage to tanks. A bullet never damages a tank
+// belonging to its own owning player, even if that player's fleet has
+// several tanks. Caller must hold g.mu.
+func (g *Game) resolveHits() {
+	for _, b := range g.bullets {
+		if !b.IsActive() {
+			continue
+		}
+		for id, p := range g.players {
+			if id == b.OwnerID {
+				continue
+			}
+			for _, t := range p.Tanks {
+				if !t.IsAlive() {
+					continue
+				}
+				if b.GetHitbox().Intersects(t.GetHitbox()) {
+					t.TakeDamage(b.Damage)
+				}
+			}
+		}
+	}
 }
-func (g *Game) inbounds(x_pos, y_pos float64) bool{
-	return x_pos >= 0 && x_pos < float64(g.mapWidth) && y_pos >= 0 && y_pos < float64(g.mapHeight)
+
+// Stop halts the game; the lobby calls this when tearing down.
+func (g *Game) Stop() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.state = GameOver
+}
+
+// Snapshot is a point-in-time copy of everything a client needs to resume
+// or render a match.
+type Snapshot struct {
+	State     GameState            `json:"state"`
+	Players   map[string]TankState `json:"players"`
+	Bullets   []BulletState        `json:"bullets"`
+	Splosions []SplosionState      `json:"splosions"`
+}
+
+// GetSnapshot returns the current authoritative state of the game, keyed
+// by tank ID rather than player ID since a player's fleet can hold several
+// tanks at once.
+func (g *Game) GetSnapshot() Snapshot {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	now := g.clock.Now()
+
+	players := make(map[string]TankState)
+	for _, p := range g.players {
+		for _, t := range p.Tanks {
+			players[t.ID] = t.ToState()
+		}
+	}
+
+	bullets := make([]BulletState, 0, len(g.bullets))
+	for _, b := range g.bullets {
+		bullets = append(bullets, b.ToState(now))
+	}
+
+	splosions := make([]SplosionState, 0, len(g.splosions))
+	for _, s := range g.splosions {
+		splosions = append(splosions, s.ToState())
+	}
+
+	return Snapshot{
+		State:     g.state,
+		Players:   players,
+		Bullets:   bullets,
+		Splosions: splosions,
+	}
 }
-type Player struct{
-	alive bool
-	x, y float64
-	keys byte
-	look byte
+
+// VisibleSnapshot is the fog-of-war-filtered view of the match sent to a
+// single player: full detail for Players (itself, plus any ally sharing
+// its scanner visibility), TruncatedTankState for every enemy it can see,
+// and nothing at all for tanks outside its scanner range or line of sight.
+type VisibleSnapshot struct {
+	State     GameState                     `json:"state"`
+	Players   map[string]TankState          `json:"players"`
+	Enemies   map[string]TruncatedTankState `json:"enemies"`
+	Bullets   []BulletState                 `json:"bullets"`
+	Splosions []SplosionState               `json:"splosions"`
 }
 
+// GetVisibleSnapshot returns the match state visible to viewerID. A viewer
+// with a fleet in the match sees its own tanks in full (they're allies of
+// each other by construction) and every enemy tank within scanner radius
+// and line of sight of any of its own tanks as a TruncatedTankState;
+// Bullets and Splosions are filtered the same way, by their owner or
+// current position, so a fogged enemy's tank can't be given away by
+// watching its bullets or mortar impacts instead. A viewer with no fleet
+// (a spectator, or a playback observer) sees everything in full, since
+// there's no tank of theirs to protect from being scouted.
+func (g *Game) GetVisibleSnapshot(viewerID string) VisibleSnapshot {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	now := g.clock.Now()
+
+	viewer, hasViewer := g.players[viewerID]
+	if !hasViewer {
+		players := make(map[string]TankState)
+		for _, p := range g.players {
+			for _, t := range p.Tanks {
+				players[t.ID] = t.ToState()
+			}
+		}
+
+		bullets := make([]BulletState, 0, len(g.bullets))
+		for _, b := range g.bullets {
+			bullets = append(bullets, b.ToState(now))
+		}
 
+		splosions := make([]SplosionState, 0, len(g.splosions))
+		for _, s := range g.splosions {
+			splosions = append(splosions, s.ToState())
+		}
 
+		return VisibleSnapshot{State: g.state, Players: players, Bullets: bullets, Splosions: splosions}
+	}
 
+	players := make(map[string]TankState, len(viewer.Tanks))
+	for _, t := range viewer.Tanks {
+		players[t.ID] = t.ToState()
+	}
 
+	enemies := make(map[string]TruncatedTankState)
+	for id, p := range g.players {
+		if id == viewerID {
+			continue
+		}
+		for _, target := range p.Tanks {
+			if !g.canSee(viewer, target) {
+				continue
+			}
+			enemies[target.ID] = target.ToTruncatedState()
+		}
+	}
 
+	bullets := make([]BulletState, 0, len(g.bullets))
+	for _, b := range g.bullets {
+		if b.OwnerID == viewerID || g.canSeePoint(viewer, b.Position) {
+			bullets = append(bullets, b.ToState(now))
+		}
+	}
+
+	splosions := make([]SplosionState, 0, len(g.splosions))
+	for _, s := range g.splosions {
+		if s.OwnerID == viewerID || g.canSeePoint(viewer, s.Center) {
+			splosions = append(splosions, s.ToState())
+		}
+	}
+
+	return VisibleSnapshot{State: g.state, Players: players, Enemies: enemies, Bullets: bullets, Splosions: splosions}
+}
+
+// canSeePoint reports whether pos is visible to viewer's fleet: within
+// scanner radius and line of sight of at least one of viewer's tanks.
+// Caller must hold g.mu.
+func (g *Game) canSeePoint(viewer *Player, pos Vector2) bool {
+	for _, t := range viewer.Tanks {
+		if t.Position.Distance(pos) > t.ScannerRadius {
+			continue
+		}
+		if HasLineOfSight(t.Position, pos, g.obstacles) {
+			return true
+		}
+	}
+	return false
+}
+
+// canSee reports whether target is visible to viewer's fleet; see
+// canSeePoint. Caller must hold g.mu.
+func (g *Game) canSee(viewer *Player, target *Tank) bool {
+	return g.canSeePoint(viewer, target.Position)
+}
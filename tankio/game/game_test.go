@@ -0,0 +1,35 @@
+package game
+
+import (
+	"testing"
+
+	"tankio/config"
+)
+
+// TestAddPlayerAvoidsSpawningInsideObstacle covers a fleet's fanned-out
+// tanks landing inside an obstacle even though the base spawn point is
+// clear of it: GenerateObstacles only keeps obstacles off the base spawn
+// point itself, and a large enough fleet can fan out past that clearance.
+func TestAddPlayerAvoidsSpawningInsideObstacle(t *testing.T) {
+	cfg := config.Default()
+	cfg.TanksPerPlayer = 4
+	g := NewGame(cfg)
+
+	base := g.spawnPoints[0]
+	blocker := Obstacle{
+		ID:            "blocker",
+		Type:          ObstacleRectangle,
+		Rect:          Rectangle{X: base.X - 40, Y: base.Y - 150, Width: 80, Height: 250},
+		BlocksTanks:   true,
+		BlocksBullets: true,
+	}
+	g.obstacles = []Obstacle{blocker}
+
+	player := g.AddPlayer("p1")
+
+	for _, tank := range player.Tanks {
+		if blocker.Rect.Contains(tank.Position) {
+			t.Errorf("tank %s spawned inside the obstacle at %+v", tank.ID, tank.Position)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package game
+
+import "math"
+
+// Collision is the nearest thing a raycast query hit along its segment:
+// an obstacle or an enemy tank. Point is where the ray crossed it.
+type Collision struct {
+	Point    Vector2 `json:"point"`
+	Type     string  `json:"type"` // "obstacle" or "tank"
+	TargetID string  `json:"targetId"`
+}
+
+// RaySegmentIntersect reports the nearest point, as t in [0,1] along the
+// ray from a to b, at which that ray crosses the segment from c to d.
+func RaySegmentIntersect(a, b, c, d Vector2) (float64, bool) {
+	r := b.Sub(a)
+	s := d.Sub(c)
+
+	denom := cross(r, s)
+	if denom == 0 {
+		// Parallel (or collinear); treat as a miss rather than special-casing
+		// the collinear-overlap case, which a raycast query doesn't need.
+		return 0, false
+	}
+
+	qp := c.Sub(a)
+	t := cross(qp, s) / denom
+	u := cross(qp, r) / denom
+	if t < 0 || t > 1 || u < 0 || u > 1 {
+		return 0, false
+	}
+	return t, true
+}
+
+// RayCircleIntersect reports the nearest point, as t in [0,1] along the
+// ray from a to b, at which that ray enters c. If a already starts inside
+// c, t is 0.
+func RayCircleIntersect(a, b Vector2, c Circle) (float64, bool) {
+	d := b.Sub(a)
+	f := a.Sub(c.Center)
+
+	A := d.Dot(d)
+	B := 2 * f.Dot(d)
+	C := f.Dot(f) - c.Radius*c.Radius
+
+	disc := B*B - 4*A*C
+	if disc < 0 {
+		return 0, false
+	}
+	disc = math.Sqrt(disc)
+
+	t1 := (-B - disc) / (2 * A)
+	t2 := (-B + disc) / (2 * A)
+	switch {
+	case t1 >= 0 && t1 <= 1:
+		return t1, true
+	case t1 < 0 && t2 >= 0:
+		// a started inside the circle; the ray is already colliding at its
+		// origin.
+		return 0, true
+	default:
+		return 0, false
+	}
+}
+
+// RayRectIntersect reports the nearest point, as t in [0,1] along the ray
+// from a to b, at which that ray crosses one of r's four edges. If a
+// already starts inside r, t is 0.
+func RayRectIntersect(a, b Vector2, r Rectangle) (float64, bool) {
+	if r.Contains(a) {
+		return 0, true
+	}
+
+	corners := [4]Vector2{
+		{X: r.X, Y: r.Y},
+		{X: r.X + r.Width, Y: r.Y},
+		{X: r.X + r.Width, Y: r.Y + r.Height},
+		{X: r.X, Y: r.Y + r.Height},
+	}
+
+	best := math.Inf(1)
+	hit := false
+	for i := range corners {
+		if t, ok := RaySegmentIntersect(a, b, corners[i], corners[(i+1)%len(corners)]); ok && t < best {
+			best = t
+			hit = true
+		}
+	}
+	return best, hit
+}
+
+// RayIntersect reports the nearest point, as t in [0,1] along the ray from
+// a to b, at which that ray crosses the obstacle, regardless of its shape.
+func (o Obstacle) RayIntersect(a, b Vector2) (float64, bool) {
+	if o.Type == ObstacleCircle {
+		return RayCircleIntersect(a, b, o.Circle)
+	}
+	return RayRectIntersect(a, b, o.Rect)
+}
+
+// Probe raycasts from playerID's currently selected tank's turret origin
+// toward target, returning the nearest Collision with a bullet-blocking
+// obstacle or a living enemy tank along the way, or nil if the ray reaches
+// target unobstructed. Unlike an Effect, it never mutates game state, so
+// the network layer calls it directly instead of going through Enqueue.
+func (g *Game) Probe(playerID string, target Vector2) *Collision {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	p, ok := g.players[playerID]
+	if !ok {
+		return nil
+	}
+	tank, ok := p.Tank(p.Selected)
+	if !ok {
+		return nil
+	}
+
+	origin := tank.Position.Add(FromAngle(tank.TurretAngle).Scale(TankTurretLength))
+
+	var best Collision
+	bestT := math.Inf(1)
+	hit := false
+
+	for _, o := range g.obstacles {
+		if !o.BlocksBullets {
+			continue
+		}
+		if t, ok := o.RayIntersect(origin, target); ok && t < bestT {
+			bestT, hit = t, true
+			best = Collision{Point: origin.Add(target.Sub(origin).Scale(t)), Type: "obstacle", TargetID: o.ID}
+		}
+	}
+
+	for id, other := range g.players {
+		if id == playerID {
+			continue
+		}
+		for _, ot := range other.Tanks {
+			if !ot.IsAlive() {
+				continue
+			}
+			if t, ok := RayCircleIntersect(origin, target, ot.GetHitbox()); ok && t < bestT {
+				bestT, hit = t, true
+				best = Collision{Point: origin.Add(target.Sub(origin).Scale(t)), Type: "tank", TargetID: ot.ID}
+			}
+		}
+	}
+
+	if !hit {
+		return nil
+	}
+	return &best
+}
@@ -0,0 +1,96 @@
+package game
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRaySegmentIntersect(t *testing.T) {
+	tests := []struct {
+		name       string
+		a, b, c, d Vector2
+		wantHit    bool
+		wantT      float64
+	}{
+		{
+			name: "crosses a perpendicular segment at its midpoint",
+			a:    Vector2{X: 0, Y: 0}, b: Vector2{X: 10, Y: 0},
+			c: Vector2{X: 5, Y: -5}, d: Vector2{X: 5, Y: 5},
+			wantHit: true, wantT: 0.5,
+		},
+		{
+			name: "misses a segment beyond the ray's far end",
+			a:    Vector2{X: 0, Y: 0}, b: Vector2{X: 10, Y: 0},
+			c: Vector2{X: 20, Y: -5}, d: Vector2{X: 20, Y: 5},
+			wantHit: false,
+		},
+		{
+			name: "misses a segment the ray doesn't reach sideways",
+			a:    Vector2{X: 0, Y: 0}, b: Vector2{X: 10, Y: 0},
+			c: Vector2{X: 5, Y: 1}, d: Vector2{X: 5, Y: 5},
+			wantHit: false,
+		},
+		{
+			name: "parallel segments never intersect",
+			a:    Vector2{X: 0, Y: 0}, b: Vector2{X: 10, Y: 0},
+			c: Vector2{X: 0, Y: 1}, d: Vector2{X: 10, Y: 1},
+			wantHit: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := RaySegmentIntersect(tt.a, tt.b, tt.c, tt.d)
+			if ok != tt.wantHit {
+				t.Fatalf("RaySegmentIntersect() hit = %v, want %v", ok, tt.wantHit)
+			}
+			if ok && math.Abs(got-tt.wantT) > 1e-9 {
+				t.Errorf("RaySegmentIntersect() t = %v, want %v", got, tt.wantT)
+			}
+		})
+	}
+}
+
+func TestRayCircleIntersect(t *testing.T) {
+	circle := Circle{Center: Vector2{X: 10, Y: 0}, Radius: 2}
+
+	tests := []struct {
+		name    string
+		a, b    Vector2
+		wantHit bool
+		wantT   float64
+	}{
+		{
+			name: "ray enters the circle from outside",
+			a:    Vector2{X: 0, Y: 0}, b: Vector2{X: 20, Y: 0},
+			wantHit: true, wantT: 0.4, // enters at x=8, t=8/20
+		},
+		{
+			name: "ray starting inside the circle hits immediately",
+			a:    Vector2{X: 10, Y: 0}, b: Vector2{X: 20, Y: 0},
+			wantHit: true, wantT: 0,
+		},
+		{
+			name: "ray passing well clear of the circle misses",
+			a:    Vector2{X: 0, Y: 10}, b: Vector2{X: 20, Y: 10},
+			wantHit: false,
+		},
+		{
+			name: "ray that would hit the circle's line but stops short misses",
+			a:    Vector2{X: 0, Y: 0}, b: Vector2{X: 5, Y: 0},
+			wantHit: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := RayCircleIntersect(tt.a, tt.b, circle)
+			if ok != tt.wantHit {
+				t.Fatalf("RayCircleIntersect() hit = %v, want %v", ok, tt.wantHit)
+			}
+			if ok && math.Abs(got-tt.wantT) > 1e-9 {
+				t.Errorf("RayCircleIntersect() t = %v, want %v", got, tt.wantT)
+			}
+		})
+	}
+}
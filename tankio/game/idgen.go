@@ -0,0 +1,35 @@
+package game
+
+import (
+	"strconv"
+	"sync"
+)
+
+// IDGenerator assigns short IDs to bullets. Seeding it with the same value
+// and replaying the same sequence of Fire calls reproduces the exact IDs a
+// live match produced, which playback relies on to stay byte-for-byte in
+// sync with what was recorded.
+type IDGenerator struct {
+	mu      sync.Mutex
+	counter uint64
+}
+
+// NewIDGenerator creates a generator whose first call to Next starts
+// counting up from seed+1. seed is reinterpreted as a uint64 bit pattern
+// rather than used as a signed counter, so a negative seed (e.g. from
+// generateSeed's full-range random draw) still yields a valid starting
+// point instead of producing negative intermediate values.
+func NewIDGenerator(seed int64) *IDGenerator {
+	return &IDGenerator{counter: uint64(seed)}
+}
+
+// Next returns the next ID in sequence: the counter's base-36 encoding.
+// That's the full 2^64 range of the counter rather than the 130 distinct
+// values a two-digit mod-26/mod-10 scheme allowed, so a long match can't
+// mint duplicate bullet IDs.
+func (g *IDGenerator) Next() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.counter++
+	return strconv.FormatUint(g.counter, 36)
+}
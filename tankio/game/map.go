@@ -1,5 +1,10 @@
 package game
 
+import (
+	"fmt"
+	"math/rand"
+)
+
 // MapConfig holds the map dimensions and settings
 type MapConfig struct {
 	Width  float64 `json:"width"`
@@ -24,23 +29,162 @@ func (m MapConfig) GetBounds() Rectangle {
 	}
 }
 
-// GetSpawnPoints returns spawn positions for players
-func (m MapConfig) GetSpawnPoints() []Vector2 {
-	// Spawn players on opposite sides of the map
-	return []Vector2{
-		{X: 100, Y: m.Height / 2},                // Left side
-		{X: m.Width - 100, Y: m.Height / 2},      // Right side
+// GetSpawnPoints returns n distinct spawn positions for players, alternating
+// between the map's left and right edges and spreading rows evenly down
+// each edge so no two players ever share a point. n=2 reproduces the
+// original left/right-at-mid-height layout exactly.
+func (m MapConfig) GetSpawnPoints(n int) []Vector2 {
+	if n < 1 {
+		n = 1
+	}
+	rows := (n + 1) / 2
+	points := make([]Vector2, n)
+	for i := 0; i < n; i++ {
+		x := 100.0
+		if i%2 == 1 {
+			x = m.Width - 100 // Right side
+		}
+		row := i/2 + 1
+		points[i] = Vector2{X: x, Y: m.Height * float64(row) / float64(rows+1)}
 	}
+	return points
+}
+
+// ObstacleType distinguishes the two shapes the map generator can place.
+type ObstacleType string
+
+const (
+	ObstacleCircle    ObstacleType = "circle"
+	ObstacleRectangle ObstacleType = "rectangle"
+)
+
+// Obstacle is a static arena feature tanks collide with and bullets may be
+// blocked by. Type selects which of Circle or Rect is populated; keeping
+// obstacles one concrete struct, rather than an interface with a type per
+// shape, is what lets the whole list serialize directly into the
+// MsgTypeMapInit payload without a custom marshaler.
+type Obstacle struct {
+	ID            string       `json:"id"`
+	Type          ObstacleType `json:"type"`
+	Circle        Circle       `json:"circle"`
+	Rect          Rectangle    `json:"rect"`
+	BlocksTanks   bool         `json:"blocksTanks"`
+	BlocksBullets bool         `json:"blocksBullets"`
 }
 
-// Obstacle interface for future wall/rock implementations
-type Obstacle interface {
-	GetBounds() Rectangle
-	BlocksBullets() bool
-	BlocksTanks() bool
-	GetType() string
+// GetBounds returns the obstacle's axis-aligned bounding box regardless of
+// its shape.
+func (o Obstacle) GetBounds() Rectangle {
+	if o.Type == ObstacleCircle {
+		return Rectangle{
+			X:      o.Circle.Center.X - o.Circle.Radius,
+			Y:      o.Circle.Center.Y - o.Circle.Radius,
+			Width:  o.Circle.Radius * 2,
+			Height: o.Circle.Radius * 2,
+		}
+	}
+	return o.Rect
 }
 
-// TODO: Implement Rock and Wall obstacles
-// type Rock struct { ... }
-// type Wall struct { ... }
+// IntersectsCircle reports whether c overlaps the obstacle.
+func (o Obstacle) IntersectsCircle(c Circle) bool {
+	if o.Type == ObstacleCircle {
+		return o.Circle.Intersects(c)
+	}
+	return o.Rect.IntersectsCircle(c)
+}
+
+// PushOut returns where c's center should move to so it just clears the
+// obstacle, sliding along its edge instead of being stopped dead.
+func (o Obstacle) PushOut(c Circle) Vector2 {
+	if o.Type == ObstacleCircle {
+		return PushOutOfCircle(o.Circle, c)
+	}
+	return PushOutOfRect(o.Rect, c)
+}
+
+// IntersectsSegment reports whether the line segment from a to b passes
+// through the obstacle, regardless of its shape.
+func (o Obstacle) IntersectsSegment(a, b Vector2) bool {
+	if o.Type == ObstacleCircle {
+		return o.Circle.IntersectsSegment(a, b)
+	}
+	return o.Rect.IntersectsSegment(a, b)
+}
+
+// HasLineOfSight reports whether a straight line from a to b is unobstructed
+// by any sight-blocking obstacle. An obstacle that blocks bullets is solid
+// enough to block sight too, so the scanner reuses that same flag rather
+// than needing an obstacle-authoring step of its own.
+func HasLineOfSight(a, b Vector2, obstacles []Obstacle) bool {
+	for _, o := range obstacles {
+		if o.BlocksBullets && o.IntersectsSegment(a, b) {
+			return false
+		}
+	}
+	return true
+}
+
+// obstacleSpawnClearance keeps generated obstacles off spawn points so a
+// tank never starts the match already wedged against one.
+const obstacleSpawnClearance = 120.0
+
+// GenerateObstacles deterministically places count obstacles within bounds,
+// alternating circle and rectangle shapes, using seed as the source for a
+// seeded RNG. A playback lobby calls this with the same seed recorded from
+// the live match, reproducing the exact same arena.
+func GenerateObstacles(bounds Rectangle, spawnPoints []Vector2, seed int64, count int) []Obstacle {
+	rng := rand.New(rand.NewSource(seed))
+	obstacles := make([]Obstacle, 0, count)
+
+	for i := 0; i < count; i++ {
+		center := Vector2{X: bounds.X + bounds.Width/2, Y: bounds.Y + bounds.Height/2}
+		for attempt := 0; attempt < 20; attempt++ {
+			candidate := Vector2{
+				X: bounds.X + rng.Float64()*bounds.Width,
+				Y: bounds.Y + rng.Float64()*bounds.Height,
+			}
+			if !tooCloseToSpawn(candidate, spawnPoints) {
+				center = candidate
+				break
+			}
+		}
+
+		id := fmt.Sprintf("obstacle-%d", i)
+		if i%2 == 0 {
+			obstacles = append(obstacles, Obstacle{
+				ID:            id,
+				Type:          ObstacleCircle,
+				Circle:        Circle{Center: center, Radius: 20 + rng.Float64()*30},
+				BlocksTanks:   true,
+				BlocksBullets: true,
+			})
+			continue
+		}
+
+		w, h := 40+rng.Float64()*60, 40+rng.Float64()*60
+		obstacles = append(obstacles, Obstacle{
+			ID:   id,
+			Type: ObstacleRectangle,
+			Rect: Rectangle{
+				X:      center.X - w/2,
+				Y:      center.Y - h/2,
+				Width:  w,
+				Height: h,
+			},
+			BlocksTanks:   true,
+			BlocksBullets: true,
+		})
+	}
+
+	return obstacles
+}
+
+func tooCloseToSpawn(p Vector2, spawnPoints []Vector2) bool {
+	for _, s := range spawnPoints {
+		if p.Distance(s) < obstacleSpawnClearance {
+			return true
+		}
+	}
+	return false
+}
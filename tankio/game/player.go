@@ -0,0 +1,61 @@
+package game
+
+// Player owns every tank spawned for one connected client. Most lobbies
+// give a player a single tank; a lobby configured with a larger
+// TanksPerPlayer spawns a fleet instead, and Selected names the one tank
+// that reacts to movement and aim input -- the rest hold position until
+// MsgTypeSelectTank switches focus to them.
+type Player struct {
+	ID       string
+	Tanks    []*Tank
+	Selected string
+}
+
+// NewPlayer groups tanks under a player, focusing input on the first one.
+func NewPlayer(id string, tanks []*Tank) *Player {
+	p := &Player{ID: id, Tanks: tanks}
+	if len(tanks) > 0 {
+		p.Selected = tanks[0].ID
+	}
+	return p
+}
+
+// Tank returns the player's tank with the given ID, if it has one.
+func (p *Player) Tank(tankID string) (*Tank, bool) {
+	for _, t := range p.Tanks {
+		if t.ID == tankID {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// Select changes which of the player's tanks reacts to movement and aim
+// input, reporting false if tankID doesn't name one the player owns.
+func (p *Player) Select(tankID string) bool {
+	if _, ok := p.Tank(tankID); !ok {
+		return false
+	}
+	p.Selected = tankID
+	return true
+}
+
+// IsAlive reports whether any of the player's tanks still has health.
+func (p *Player) IsAlive() bool {
+	for _, t := range p.Tanks {
+		if t.IsAlive() {
+			return true
+		}
+	}
+	return false
+}
+
+// TankIDs returns the IDs of every tank in the player's fleet, in spawn
+// order, so a client can learn its own tank roster on connect.
+func (p *Player) TankIDs() []string {
+	ids := make([]string, len(p.Tanks))
+	for i, t := range p.Tanks {
+		ids[i] = t.ID
+	}
+	return ids
+}
@@ -0,0 +1,91 @@
+package game
+
+// mortarSplosionTTL is how long a mortar's blast shockwave stays in the
+// broadcast state for clients to render after it deals damage.
+const mortarSplosionTTL = 0.6
+
+// Splosion is a momentary area-of-effect blast, spawned the tick a mortar
+// shell reaches its target. Every living enemy tank whose hitbox overlaps
+// the circle of Radius around Center takes damage the instant it spawns,
+// scaled by linear falloff
+// from the center; after that it just counts down TTL so clients have
+// time to render the shockwave before it's dropped from the broadcast.
+type Splosion struct {
+	Center  Vector2 `json:"center"`
+	Radius  float64 `json:"radius"`
+	Damage  int     `json:"damage"`
+	TTL     float64 `json:"ttl"`
+	OwnerID string  `json:"ownerId"`
+}
+
+// Update counts down the splosion's remaining TTL and reports whether it
+// should still be kept around for clients to render.
+func (s *Splosion) Update(dt float64) bool {
+	s.TTL -= dt
+	return s.TTL > 0
+}
+
+// SplosionState is the JSON-serializable state sent to clients so they
+// can render a shockwave for the splosion's remaining TTL.
+type SplosionState struct {
+	Center Vector2 `json:"center"`
+	Radius float64 `json:"radius"`
+	TTL    float64 `json:"ttl"`
+}
+
+// ToState converts a splosion to its client-visible state.
+func (s *Splosion) ToState() SplosionState {
+	return SplosionState{Center: s.Center, Radius: s.Radius, TTL: s.TTL}
+}
+
+// spawnSplosion creates a blast at a mortar shell's impact point and
+// immediately applies its falloff damage. Caller must hold g.mu.
+func (g *Game) spawnSplosion(b *Bullet) {
+	s := &Splosion{
+		Center:  b.ImpactPos,
+		Radius:  b.ImpactRadius,
+		Damage:  b.Damage,
+		TTL:     mortarSplosionTTL,
+		OwnerID: b.OwnerID,
+	}
+	g.splosions = append(g.splosions, s)
+	g.applySplosionDamage(s)
+}
+
+// applySplosionDamage damages every living enemy tank whose hitbox
+// intersects s's blast circle, scaled by 1 - distance/(Radius+hitbox
+// radius) so a tank clipping the blast edge takes a graze and one at the
+// center takes it full force. A splosion never damages its own owner's
+// fleet. Caller must hold g.mu.
+func (g *Game) applySplosionDamage(s *Splosion) {
+	for id, p := range g.players {
+		if id == s.OwnerID {
+			continue
+		}
+		for _, t := range p.Tanks {
+			if !t.IsAlive() {
+				continue
+			}
+			reach := s.Radius + TankSize/2
+			dist := t.Position.Distance(s.Center)
+			if dist > reach {
+				continue
+			}
+			if dmg := int(float64(s.Damage) * (1 - dist/reach)); dmg > 0 {
+				t.TakeDamage(dmg)
+			}
+		}
+	}
+}
+
+// updateSplosions ages out every splosion whose TTL has elapsed. Caller
+// must hold g.mu.
+func (g *Game) updateSplosions(dt float64) {
+	alive := g.splosions[:0]
+	for _, s := range g.splosions {
+		if s.Update(dt) {
+			alive = append(alive, s)
+		}
+	}
+	g.splosions = alive
+}
@@ -0,0 +1,45 @@
+package game
+
+import "testing"
+
+func TestApplySplosionDamageFalloff(t *testing.T) {
+	g := New()
+
+	owner := g.AddPlayer("owner")
+	victim := g.AddPlayer("victim")
+	clipping := g.AddPlayer("clipping-edge")
+	outOfRange := g.AddPlayer("out-of-range")
+
+	center := Vector2{X: 0, Y: 0}
+	const radius = 100.0
+	const damage = 100
+
+	owner.Tanks[0].Position = center // never damaged: same fleet as the splosion's owner
+	victim.Tanks[0].Position = Vector2{X: 50, Y: 0}
+	clipping.Tanks[0].Position = Vector2{X: 110, Y: 0} // past radius, but hitbox still overlaps it
+	outOfRange.Tanks[0].Position = Vector2{X: 200, Y: 0}
+
+	s := &Splosion{Center: center, Radius: radius, Damage: damage, OwnerID: "owner"}
+
+	g.mu.Lock()
+	g.applySplosionDamage(s)
+	g.mu.Unlock()
+
+	if got, want := owner.Tanks[0].Health, owner.Tanks[0].MaxHealth; got != want {
+		t.Errorf("owner tank took damage from its own splosion: health = %d, want untouched %d", got, want)
+	}
+
+	reach := radius + TankSize/2.0
+	wantVictimDamage := int(float64(damage) * (1 - 50.0/reach))
+	if got := victim.Tanks[0].MaxHealth - victim.Tanks[0].Health; got != wantVictimDamage {
+		t.Errorf("victim at half radius took %d damage, want %d (linear falloff)", got, wantVictimDamage)
+	}
+
+	if got := clipping.Tanks[0].MaxHealth - clipping.Tanks[0].Health; got <= 0 {
+		t.Errorf("tank whose hitbox clips the blast edge took no damage: health = %d, want < %d", clipping.Tanks[0].Health, clipping.Tanks[0].MaxHealth)
+	}
+
+	if got, want := outOfRange.Tanks[0].Health, outOfRange.Tanks[0].MaxHealth; got != want {
+		t.Errorf("tank outside radius took damage: health = %d, want untouched %d", got, want)
+	}
+}
@@ -2,6 +2,8 @@ package game
 
 import (
 	"math"
+
+	"tankio/config"
 )
 
 const (
@@ -18,15 +20,40 @@ type Tank struct {
 	TurretAngle   float64    `json:"turretAngle"`   // Turret rotation (aim direction)
 	Health        int        `json:"health"`
 	MaxHealth     int        `json:"maxHealth"`
-	Speed         float64    `json:"-"`
 	ActiveWeapon  WeaponType `json:"activeWeapon"`
 	Cannon        *Cannon    `json:"-"`
 	Mortar        *Mortar    `json:"-"`
+	ScannerRadius float64    `json:"-"`
+
+	Stats          TankStats `json:"-"` // Movement feel; tradeable via ConfigureTankEffect
+	CurrentSpeed   float64   `json:"-"` // Signed speed along Rotation, eased toward TargetSpeed
+	TargetSpeed    float64   `json:"-"` // Speed CurrentSpeed accelerates toward this tick
+	DesiredHeading float64   `json:"-"` // Heading Rotation turns toward this tick
 
 	// Input state (received from client)
 	Input InputState `json:"-"`
 }
 
+// TankStats controls a tank's movement feel: how fast it can go, how
+// quickly it gets there, and how fast it can turn to face a new heading.
+// A player can trade MaxHealth for a higher MaxSpeed via
+// ConfigureTankEffect before the match starts.
+type TankStats struct {
+	MaxSpeed     float64 `json:"maxSpeed"`
+	Acceleration float64 `json:"acceleration"` // Pixels per second^2
+	TurnSpeed    float64 `json:"turnSpeed"`    // Radians per second
+}
+
+// DefaultTankStats returns the movement feel every tank spawns with,
+// before any ConfigureTankEffect trades health for speed.
+func DefaultTankStats() TankStats {
+	return TankStats{
+		MaxSpeed:     TankSpeed,
+		Acceleration: 400,
+		TurnSpeed:    math.Pi * 2,
+	}
+}
+
 // InputState represents the current input from the player
 type InputState struct {
 	Up       bool    `json:"up"`
@@ -38,72 +65,128 @@ type InputState struct {
 	Firing   bool    `json:"firing"`
 }
 
-// NewTank creates a new tank at the given position
-func NewTank(id string, pos Vector2) *Tank {
+// NewTank creates a new tank at the given position, with weapons tuned by
+// cfg, a scanner whose range is scannerRadius, and firing against
+// clock/ids so bullet timing and IDs stay reproducible under playback.
+func NewTank(id string, pos Vector2, cfg config.WeaponConfig, clock Clock, ids *IDGenerator, scannerRadius float64) *Tank {
 	return &Tank{
-		ID:           id,
-		Position:     pos,
-		Rotation:     0,
-		TurretAngle:  0,
-		Health:       100,
-		MaxHealth:    100,
-		Speed:        TankSpeed,
-		ActiveWeapon: WeaponCannon,
-		Cannon:       NewCannon(),
-		Mortar:       NewMortar(),
+		ID:            id,
+		Position:      pos,
+		Rotation:      0,
+		TurretAngle:   0,
+		Health:        100,
+		MaxHealth:     100,
+		ActiveWeapon:  WeaponCannon,
+		Cannon:        NewCannon(cfg.Cannon, clock, ids),
+		Mortar:        NewMortar(cfg.Mortar, clock, ids),
+		ScannerRadius: scannerRadius,
+		Stats:         DefaultTankStats(),
 	}
 }
 
-// Update processes tank movement and state for one frame
-func (t *Tank) Update(dt float64, mapBounds Rectangle) {
-	// Calculate movement direction from input
-	var moveDir Vector2
-	if t.Input.Up {
-		moveDir.Y -= 1
-	}
-	if t.Input.Down {
-		moveDir.Y += 1
-	}
-	if t.Input.Left {
-		moveDir.X -= 1
-	}
-	if t.Input.Right {
-		moveDir.X += 1
-	}
+// Update processes tank movement and state for one frame. owner is the
+// player this tank belongs to; only the player's currently selected tank
+// reacts to movement and aim input, so the rest of a fleet holds its
+// ground until MsgTypeSelectTank focuses them.
+func (t *Tank) Update(owner *Player, dt float64, mapBounds Rectangle, obstacles []Obstacle) {
+	if owner.Selected == t.ID {
+		// Calculate movement direction from input
+		var moveDir Vector2
+		if t.Input.Up {
+			moveDir.Y -= 1
+		}
+		if t.Input.Down {
+			moveDir.Y += 1
+		}
+		if t.Input.Left {
+			moveDir.X -= 1
+		}
+		if t.Input.Right {
+			moveDir.X += 1
+		}
 
-	// Apply movement
-	if moveDir.X != 0 || moveDir.Y != 0 {
-		moveDir = moveDir.Normalize()
-		newPos := t.Position.Add(moveDir.Scale(t.Speed * dt))
+		if moveDir.X != 0 || moveDir.Y != 0 {
+			moveDir = moveDir.Normalize()
+			t.DesiredHeading = moveDir.Angle()
 
-		// Update body rotation to face movement direction
-		t.Rotation = moveDir.Angle()
+			// Target speed scales with how closely the desired heading
+			// matches the way the tank is already facing, so a tank has to
+			// turn toward its travel direction before it can reach full
+			// speed, rather than strafing instantly at any angle.
+			heading := FromAngle(t.Rotation)
+			t.TargetSpeed = t.Stats.MaxSpeed * (heading.X*moveDir.X + heading.Y*moveDir.Y)
+		} else {
+			t.TargetSpeed = 0
+		}
 
-		// Clamp to map bounds
-		halfSize := TankSize / 2
-		newPos.X = math.Max(mapBounds.X+halfSize, math.Min(newPos.X, mapBounds.X+mapBounds.Width-halfSize))
-		newPos.Y = math.Max(mapBounds.Y+halfSize, math.Min(newPos.Y, mapBounds.Y+mapBounds.Height-halfSize))
+		t.Rotation += clampAngleDiff(t.Rotation, t.DesiredHeading, t.Stats.TurnSpeed*dt)
 
-		t.Position = newPos
-	}
+		if t.CurrentSpeed < t.TargetSpeed {
+			t.CurrentSpeed = math.Min(t.CurrentSpeed+t.Stats.Acceleration*dt, t.TargetSpeed)
+		} else if t.CurrentSpeed > t.TargetSpeed {
+			t.CurrentSpeed = math.Max(t.CurrentSpeed-t.Stats.Acceleration*dt, t.TargetSpeed)
+		}
+
+		if t.CurrentSpeed != 0 {
+			newPos := t.Position.Add(FromAngle(t.Rotation).Scale(t.CurrentSpeed * dt))
+
+			// Clamp to map bounds
+			halfSize := TankSize / 2
+			newPos.X = math.Max(mapBounds.X+halfSize, math.Min(newPos.X, mapBounds.X+mapBounds.Width-halfSize))
+			newPos.Y = math.Max(mapBounds.Y+halfSize, math.Min(newPos.Y, mapBounds.Y+mapBounds.Height-halfSize))
 
-	// Update turret angle to point at mouse
-	mousePos := Vector2{X: t.Input.MouseX, Y: t.Input.MouseY}
-	toMouse := mousePos.Sub(t.Position)
-	t.TurretAngle = toMouse.Angle()
+			// Slide along whichever obstacle edge is nearest rather than
+			// stopping dead, so hugging a wall while strafing still feels smooth.
+			hitbox := Circle{Center: newPos, Radius: TankSize / 2}
+			for _, o := range obstacles {
+				if o.BlocksTanks && o.IntersectsCircle(hitbox) {
+					newPos = o.PushOut(hitbox)
+					hitbox.Center = newPos
+				}
+			}
+
+			t.Position = newPos
+		}
+
+		// Update turret angle to point at mouse
+		mousePos := Vector2{X: t.Input.MouseX, Y: t.Input.MouseY}
+		toMouse := mousePos.Sub(t.Position)
+		t.TurretAngle = toMouse.Angle()
+	}
 
 	// Update weapons
 	t.Cannon.Update(dt)
 	t.Mortar.Update(dt)
 }
 
-// Fire attempts to fire the current weapon
-func (t *Tank) Fire() *Bullet {
+// clampAngleDiff returns the signed step to turn from toward to by at
+// most maxStep radians, taking the shorter way around the circle.
+func clampAngleDiff(from, to, maxStep float64) float64 {
+	diff := to - from
+	for diff > math.Pi {
+		diff -= 2 * math.Pi
+	}
+	for diff < -math.Pi {
+		diff += 2 * math.Pi
+	}
+	if diff > maxStep {
+		return maxStep
+	}
+	if diff < -maxStep {
+		return -maxStep
+	}
+	return diff
+}
+
+// Fire attempts to fire the current weapon, attributing the resulting
+// bullet to ownerID rather than the tank's own ID, so hit resolution and
+// scoring stay scoped to the player even when its fleet has several tanks.
+func (t *Tank) Fire(ownerID string) *Bullet {
 	switch t.ActiveWeapon {
 	case WeaponCannon:
-		return t.Cannon.Fire(t.Position, t.TurretAngle, t.ID)
+		return t.Cannon.Fire(t.Position, t.TurretAngle, ownerID)
 	case WeaponMortar:
-		return t.Mortar.Fire(t.Position, t.TurretAngle, t.ID)
+		return t.Mortar.Fire(t.Position, t.TurretAngle, ownerID)
 	}
 	return nil
 }
@@ -169,3 +252,25 @@ func (t *Tank) ToState() TankState {
 		MortarMaxAmmo: t.Mortar.GetMaxAmmo(),
 	}
 }
+
+// TruncatedTankState is the state sent for a tank that's been spotted by a
+// scanner but isn't the receiving player's own: enough to render it, but
+// none of the health, ammo, or active-weapon detail that would let a
+// client read an opponent's state it hasn't earned visibility into.
+type TruncatedTankState struct {
+	ID          string  `json:"id"`
+	Position    Vector2 `json:"position"`
+	Rotation    float64 `json:"rotation"`
+	TurretAngle float64 `json:"turretAngle"`
+}
+
+// ToTruncatedState converts a tank to the limited state shown to players
+// who can see it but don't control it.
+func (t *Tank) ToTruncatedState() TruncatedTankState {
+	return TruncatedTankState{
+		ID:          t.ID,
+		Position:    t.Position,
+		Rotation:    t.Rotation,
+		TurretAngle: t.TurretAngle,
+	}
+}
@@ -0,0 +1,34 @@
+package game
+
+import (
+	"math"
+	"testing"
+)
+
+func TestClampAngleDiff(t *testing.T) {
+	const maxStep = 0.1
+
+	tests := []struct {
+		name string
+		from float64
+		to   float64
+		want float64
+	}{
+		{"already facing target", 0, 0, 0},
+		{"small positive step under max", 0, 0.05, 0.05},
+		{"small negative step under max", 0, -0.05, -0.05},
+		{"positive step clamped to max", 0, 1.0, maxStep},
+		{"negative step clamped to max", 0, -1.0, -maxStep},
+		{"wraps the short way across +/-pi", math.Pi - 0.05, -math.Pi + 0.05, maxStep},
+		{"wraps the short way the other direction", -math.Pi + 0.05, math.Pi - 0.05, -maxStep},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := clampAngleDiff(tt.from, tt.to, maxStep)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("clampAngleDiff(%v, %v, %v) = %v, want %v", tt.from, tt.to, maxStep, got, tt.want)
+			}
+		})
+	}
+}
@@ -42,6 +42,11 @@ func (v Vector2) Distance(other Vector2) float64 {
 	return v.Sub(other).Length()
 }
 
+// Dot returns the dot product of v and other.
+func (v Vector2) Dot(other Vector2) float64 {
+	return v.X*other.X + v.Y*other.Y
+}
+
 // Angle returns the angle of the vector in radians
 func (v Vector2) Angle() float64 {
 	return math.Atan2(v.Y, v.X)
@@ -71,6 +76,53 @@ func (r Rectangle) Intersects(other Rectangle) bool {
 		r.Y+r.Height > other.Y
 }
 
+// ClosestPoint returns the point on or inside the rectangle closest to p.
+func (r Rectangle) ClosestPoint(p Vector2) Vector2 {
+	return Vector2{
+		X: math.Max(r.X, math.Min(p.X, r.X+r.Width)),
+		Y: math.Max(r.Y, math.Min(p.Y, r.Y+r.Height)),
+	}
+}
+
+// IntersectsCircle checks if the rectangle overlaps a circle.
+func (r Rectangle) IntersectsCircle(c Circle) bool {
+	return r.ClosestPoint(c.Center).Distance(c.Center) <= c.Radius
+}
+
+// IntersectsSegment reports whether the line segment from a to b crosses
+// the rectangle, used for scanner line-of-sight checks.
+func (r Rectangle) IntersectsSegment(a, b Vector2) bool {
+	if r.Contains(a) || r.Contains(b) {
+		return true
+	}
+	corners := [4]Vector2{
+		{X: r.X, Y: r.Y},
+		{X: r.X + r.Width, Y: r.Y},
+		{X: r.X + r.Width, Y: r.Y + r.Height},
+		{X: r.X, Y: r.Y + r.Height},
+	}
+	for i := range corners {
+		if segmentsIntersect(a, b, corners[i], corners[(i+1)%len(corners)]) {
+			return true
+		}
+	}
+	return false
+}
+
+// segmentsIntersect reports whether segment p1-p2 crosses segment p3-p4.
+func segmentsIntersect(p1, p2, p3, p4 Vector2) bool {
+	d1 := cross(p4.Sub(p3), p1.Sub(p3))
+	d2 := cross(p4.Sub(p3), p2.Sub(p3))
+	d3 := cross(p2.Sub(p1), p3.Sub(p1))
+	d4 := cross(p2.Sub(p1), p4.Sub(p1))
+	return ((d1 > 0) != (d2 > 0)) && ((d3 > 0) != (d4 > 0))
+}
+
+// cross returns the 2D cross product (z-component) of a and b.
+func cross(a, b Vector2) float64 {
+	return a.X*b.Y - a.Y*b.X
+}
+
 // Circle represents a circular shape for collision
 type Circle struct {
 	Center Vector2
@@ -86,3 +138,56 @@ func (c Circle) Contains(p Vector2) bool {
 func (c Circle) Intersects(other Circle) bool {
 	return c.Center.Distance(other.Center) <= c.Radius+other.Radius
 }
+
+// IntersectsSegment reports whether the line segment from a to b crosses
+// the circle, used for scanner line-of-sight checks.
+func (c Circle) IntersectsSegment(a, b Vector2) bool {
+	seg := b.Sub(a)
+	segLenSq := seg.X*seg.X + seg.Y*seg.Y
+	if segLenSq == 0 {
+		return c.Contains(a)
+	}
+	t := (c.Center.Sub(a).X*seg.X + c.Center.Sub(a).Y*seg.Y) / segLenSq
+	t = math.Max(0, math.Min(1, t))
+	closest := a.Add(seg.Scale(t))
+	return c.Contains(closest)
+}
+
+// PushOutOfRect returns where c's center should move to so it just clears
+// rect, sliding along whichever edge it's nearest to rather than being
+// snapped straight back to where it came from.
+func PushOutOfRect(rect Rectangle, c Circle) Vector2 {
+	closest := rect.ClosestPoint(c.Center)
+	diff := c.Center.Sub(closest)
+	if dist := diff.Length(); dist > 0 {
+		return closest.Add(diff.Normalize().Scale(c.Radius))
+	}
+
+	// Center is inside rect (e.g. spawned on top of it): push out along
+	// whichever side is nearest.
+	left := c.Center.X - rect.X
+	right := rect.X + rect.Width - c.Center.X
+	top := c.Center.Y - rect.Y
+	bottom := rect.Y + rect.Height - c.Center.Y
+	switch math.Min(math.Min(left, right), math.Min(top, bottom)) {
+	case left:
+		return Vector2{X: rect.X - c.Radius, Y: c.Center.Y}
+	case right:
+		return Vector2{X: rect.X + rect.Width + c.Radius, Y: c.Center.Y}
+	case top:
+		return Vector2{X: c.Center.X, Y: rect.Y - c.Radius}
+	default:
+		return Vector2{X: c.Center.X, Y: rect.Y + rect.Height + c.Radius}
+	}
+}
+
+// PushOutOfCircle returns where c's center should move to so it just clears
+// other, sliding along its edge rather than being snapped straight back.
+func PushOutOfCircle(other, c Circle) Vector2 {
+	minDist := other.Radius + c.Radius
+	diff := c.Center.Sub(other.Center)
+	if dist := diff.Length(); dist > 0 {
+		return other.Center.Add(diff.Normalize().Scale(minDist))
+	}
+	return Vector2{X: other.Center.X + minDist, Y: other.Center.Y}
+}
@@ -0,0 +1,81 @@
+package game
+
+import (
+	"testing"
+
+	"tankio/config"
+)
+
+func newVisibilityTestGame(t *testing.T) *Game {
+	t.Helper()
+	cfg := config.Default()
+	cfg.ScannerRadius = 100
+	cfg.ObstacleCount = 0
+	return NewGame(cfg)
+}
+
+func TestGetVisibleSnapshotTruncatesAndOmitsEnemies(t *testing.T) {
+	g := newVisibilityTestGame(t)
+
+	viewer := g.AddPlayer("viewer")
+	near := g.AddPlayer("near")
+	far := g.AddPlayer("far")
+
+	viewer.Tanks[0].Position = Vector2{X: 0, Y: 0}
+	near.Tanks[0].Position = Vector2{X: 50, Y: 0}  // within scanner radius
+	far.Tanks[0].Position = Vector2{X: 1000, Y: 0} // well outside
+
+	snap := g.GetVisibleSnapshot("viewer")
+
+	if _, ok := snap.Players[viewer.Tanks[0].ID]; !ok {
+		t.Errorf("viewer's own tank missing from Players")
+	}
+	if _, ok := snap.Enemies[near.Tanks[0].ID]; !ok {
+		t.Errorf("enemy within scanner radius omitted from Enemies")
+	}
+	if _, ok := snap.Enemies[far.Tanks[0].ID]; ok {
+		t.Errorf("enemy outside scanner radius leaked into Enemies")
+	}
+}
+
+func TestGetVisibleSnapshotFiltersBulletsAndSplosionsByVisibility(t *testing.T) {
+	g := newVisibilityTestGame(t)
+
+	g.AddPlayer("viewer")
+	g.AddPlayer("enemy")
+
+	viewer, _ := g.GetPlayer("viewer")
+	viewer.Tanks[0].Position = Vector2{X: 0, Y: 0}
+	enemy, _ := g.GetPlayer("enemy")
+	enemy.Tanks[0].Position = Vector2{X: 1000, Y: 0} // outside scanner radius
+
+	g.bullets = append(g.bullets,
+		&Bullet{ID: "near", OwnerID: "enemy", Position: Vector2{X: 50, Y: 0}, Type: BulletNormal},
+		&Bullet{ID: "far", OwnerID: "enemy", Position: Vector2{X: 1000, Y: 0}, Type: BulletNormal},
+		&Bullet{ID: "own", OwnerID: "viewer", Position: Vector2{X: 1000, Y: 0}, Type: BulletNormal},
+	)
+	g.splosions = append(g.splosions,
+		&Splosion{Center: Vector2{X: 50, Y: 0}, Radius: 10, OwnerID: "enemy"},
+		&Splosion{Center: Vector2{X: 1000, Y: 0}, Radius: 10, OwnerID: "enemy"},
+	)
+
+	snap := g.GetVisibleSnapshot("viewer")
+
+	seen := make(map[string]bool, len(snap.Bullets))
+	for _, b := range snap.Bullets {
+		seen[b.ID] = true
+	}
+	if !seen["near"] {
+		t.Errorf("enemy bullet within scanner radius omitted")
+	}
+	if seen["far"] {
+		t.Errorf("enemy bullet outside scanner radius leaked an out-of-sight position")
+	}
+	if !seen["own"] {
+		t.Errorf("viewer's own bullet omitted even though they own it")
+	}
+
+	if len(snap.Splosions) != 1 || snap.Splosions[0].Center != (Vector2{X: 50, Y: 0}) {
+		t.Errorf("got splosions %+v, want only the one within scanner radius", snap.Splosions)
+	}
+}
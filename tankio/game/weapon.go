@@ -2,6 +2,8 @@ package game
 
 import (
 	"time"
+
+	"tankio/config"
 )
 
 // WeaponType identifies the type of weapon
@@ -24,14 +26,24 @@ type Weapon interface {
 
 // Cannon is the primary weapon - instant hit, unlimited ammo
 type Cannon struct {
-	lastFired time.Time
-	cooldown  time.Duration
+	clock Clock
+	ids   *IDGenerator
+
+	lastFired   time.Time
+	cooldown    time.Duration
+	damage      int
+	bulletSpeed float64
 }
 
-// NewCannon creates a new cannon weapon
-func NewCannon() *Cannon {
+// NewCannon creates a new cannon weapon tuned by cfg, timed by clock and
+// generating bullet IDs from ids.
+func NewCannon(cfg config.CannonConfig, clock Clock, ids *IDGenerator) *Cannon {
 	return &Cannon{
-		cooldown: 500 * time.Millisecond, // 0.5 second between shots
+		clock:       clock,
+		ids:         ids,
+		cooldown:    time.Duration(cfg.CooldownMs) * time.Millisecond,
+		damage:      cfg.Damage,
+		bulletSpeed: cfg.BulletSpeed,
 	}
 }
 
@@ -39,23 +51,24 @@ func (c *Cannon) Fire(origin Vector2, angle float64, ownerID string) *Bullet {
 	if !c.CanFire() {
 		return nil
 	}
-	c.lastFired = time.Now()
+	now := c.clock.Now()
+	c.lastFired = now
 
 	direction := FromAngle(angle)
 	return &Bullet{
-		ID:        generateID(),
+		ID:        c.ids.Next(),
 		OwnerID:   ownerID,
 		Position:  origin.Add(direction.Scale(30)), // Spawn slightly ahead of tank
-		Velocity:  direction.Scale(600),            // 600 pixels per second
+		Velocity:  direction.Scale(c.bulletSpeed),
 		Type:      BulletNormal,
-		Damage:    100, // Instant kill
-		CreatedAt: time.Now(),
+		Damage:    c.damage,
+		CreatedAt: now,
 		MaxAge:    3 * time.Second,
 	}
 }
 
 func (c *Cannon) CanFire() bool {
-	return time.Since(c.lastFired) >= c.cooldown
+	return c.clock.Now().Sub(c.lastFired) >= c.cooldown
 }
 
 func (c *Cannon) GetType() WeaponType {
@@ -76,21 +89,35 @@ func (c *Cannon) Update(dt float64) {
 
 // Mortar is the secondary weapon - delayed impact, limited ammo that recharges
 type Mortar struct {
-	lastFired     time.Time
-	cooldown      time.Duration     // Time between shots
-	ammo          int               // Current charges
-	maxAmmo       int               // Maximum charges
-	rechargeTime  time.Duration     // Time to regain one charge
-	rechargeTimer time.Duration     // Current recharge progress
-}
-
-// NewMortar creates a new mortar weapon
-func NewMortar() *Mortar {
+	clock Clock
+	ids   *IDGenerator
+
+	lastFired      time.Time
+	cooldown       time.Duration // Time between shots
+	ammo           int           // Current charges
+	maxAmmo        int           // Maximum charges
+	rechargeTime   time.Duration // Time to regain one charge
+	rechargeTimer  time.Duration // Current recharge progress
+	impactDelay    time.Duration // Flight time before the shell lands
+	impactDistance float64       // Fixed distance the shell travels
+	impactRadius   float64       // Explosion radius
+	damage         int
+}
+
+// NewMortar creates a new mortar weapon tuned by cfg, timed by clock and
+// generating bullet IDs from ids.
+func NewMortar(cfg config.MortarConfig, clock Clock, ids *IDGenerator) *Mortar {
 	return &Mortar{
-		cooldown:     3 * time.Second,  // 3 seconds between shots
-		ammo:         3,                // Start with 3 charges
-		maxAmmo:      3,
-		rechargeTime: 10 * time.Second, // 10 seconds to regain a charge
+		clock:          clock,
+		ids:            ids,
+		cooldown:       time.Duration(cfg.CooldownMs) * time.Millisecond,
+		ammo:           cfg.Ammo,
+		maxAmmo:        cfg.MaxAmmo,
+		rechargeTime:   time.Duration(cfg.RechargeMs) * time.Millisecond,
+		impactDelay:    time.Duration(cfg.ImpactDelayMs) * time.Millisecond,
+		impactDistance: cfg.ImpactDistance,
+		impactRadius:   cfg.ImpactRadius,
+		damage:         cfg.Damage,
 	}
 }
 
@@ -98,31 +125,31 @@ func (m *Mortar) Fire(origin Vector2, angle float64, ownerID string) *Bullet {
 	if !m.CanFire() {
 		return nil
 	}
-	m.lastFired = time.Now()
+	now := m.clock.Now()
+	m.lastFired = now
 	m.ammo--
 
 	direction := FromAngle(angle)
 	// Mortar lands at a fixed distance (could be cursor position in future)
-	impactDistance := 300.0
-	impactPos := origin.Add(direction.Scale(impactDistance))
+	impactPos := origin.Add(direction.Scale(m.impactDistance))
 
 	return &Bullet{
-		ID:          generateID(),
-		OwnerID:     ownerID,
-		Position:    origin,
-		Velocity:    Vector2{X: 0, Y: 0}, // Mortar doesn't move linearly
-		Type:        BulletMortar,
-		Damage:      100, // Instant kill
-		CreatedAt:   time.Now(),
-		MaxAge:      4 * time.Second,           // Max flight time
-		ImpactPos:   impactPos,
-		ImpactTime:  time.Now().Add(3 * time.Second), // 3 second delay
-		ImpactRadius: 50,                        // Explosion radius
+		ID:           m.ids.Next(),
+		OwnerID:      ownerID,
+		Position:     origin,
+		Velocity:     Vector2{X: 0, Y: 0}, // Mortar doesn't move linearly
+		Type:         BulletMortar,
+		Damage:       m.damage,
+		CreatedAt:    now,
+		MaxAge:       m.impactDelay + time.Second, // Max flight time
+		ImpactPos:    impactPos,
+		ImpactTime:   now.Add(m.impactDelay),
+		ImpactRadius: m.impactRadius,
 	}
 }
 
 func (m *Mortar) CanFire() bool {
-	return m.ammo > 0 && time.Since(m.lastFired) >= m.cooldown
+	return m.ammo > 0 && m.clock.Now().Sub(m.lastFired) >= m.cooldown
 }
 
 func (m *Mortar) GetType() WeaponType {
@@ -147,11 +174,3 @@ func (m *Mortar) Update(dt float64) {
 		}
 	}
 }
-
-// Simple ID generator (in production, use UUID)
-var idCounter int64
-
-func generateID() string {
-	idCounter++
-	return string(rune('A'+idCounter%26)) + string(rune('0'+idCounter%10))
-}
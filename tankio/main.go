@@ -7,17 +7,37 @@ import (
 	"tankio/game"
 )
 
+// localGame adapts the authoritative game.Game to Ebitengine's frame-driven
+// interface so a match can be played locally, without a server, for quick
+// manual testing.
+type localGame struct {
+	g *game.Game
+}
+
+func (l *localGame) Update() error {
+	l.g.Update(1.0 / 60.0)
+	return nil
+}
+
+func (l *localGame) Draw(screen *ebiten.Image) {
+	// TODO: draw tanks/bullets here
+}
+
+func (l *localGame) Layout(outsideWidth, outsideHeight int) (int, int) {
+	return 800, 600
+}
+
 func main() {
 	// Create your game instance
-	g := game.New()
+	lg := &localGame{g: game.New()}
 
 	// Configure the window
 	ebiten.SetWindowSize(800, 600)
 	ebiten.SetWindowTitle("Tank.io")
 
 	// Run the game loop - Ebitengine handles the loop for you
-	// It calls g.Update() and g.Draw() automatically
-	if err := ebiten.RunGame(g); err != nil {
+	// It calls lg.Update() and lg.Draw() automatically
+	if err := ebiten.RunGame(lg); err != nil {
 		log.Fatal(err)
 	}
 }
@@ -3,6 +3,7 @@ package network
 import (
 	"encoding/json"
 	"log"
+	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -25,21 +26,31 @@ const (
 
 // Client represents a connected player
 type Client struct {
-	ID       string
-	Lobby    *Lobby
-	Conn     *websocket.Conn
-	Send     chan []byte
-	done     chan struct{}
+	ID           string
+	SessionToken string
+	Resumed      bool
+	Spectator    bool
+	Lobby        *Lobby
+	Conn         *websocket.Conn
+	Send         chan []byte
+	done         chan struct{}
+	closeOnce    sync.Once
+
+	actionMu     sync.Mutex
+	lastActionAt time.Time
+	warned       bool
 }
 
 // NewClient creates a new client instance
-func NewClient(id string, conn *websocket.Conn, lobby *Lobby) *Client {
+func NewClient(id, sessionToken string, conn *websocket.Conn, lobby *Lobby) *Client {
 	return &Client{
-		ID:    id,
-		Lobby: lobby,
-		Conn:  conn,
-		Send:  make(chan []byte, 256),
-		done:  make(chan struct{}),
+		ID:           id,
+		SessionToken: sessionToken,
+		Lobby:        lobby,
+		Conn:         conn,
+		Send:         make(chan []byte, 256),
+		done:         make(chan struct{}),
+		lastActionAt: time.Now(),
 	}
 }
 
@@ -112,12 +123,21 @@ func (c *Client) WritePump() {
 			}
 
 		case <-c.done:
+			// Client.Close() fired: write a close frame the same way the
+			// Send-channel-closed branch above does, so the peer sees a
+			// clean disconnect instead of the deferred conn.Close() just
+			// dropping the TCP connection.
+			c.Conn.SetWriteDeadline(time.Now().Add(writeWait))
+			c.Conn.WriteMessage(websocket.CloseMessage, []byte{})
 			return
 		}
 	}
 }
 
-// handleMessage processes an incoming message from the client
+// handleMessage processes an incoming message from the client. The message
+// type is looked up in game.EffectRegistry rather than switched on by hand,
+// so a new ability only needs a new Effect implementation and a registry
+// entry -- nothing here has to change.
 func (c *Client) handleMessage(data []byte) {
 	var msg ClientMessage
 	if err := json.Unmarshal(data, &msg); err != nil {
@@ -125,59 +145,99 @@ func (c *Client) handleMessage(data []byte) {
 		return
 	}
 
-	switch msg.Type {
-	case MsgTypeInput:
-		c.handleInput(msg.Payload)
-	case MsgTypeFire:
-		c.handleFire()
-	case MsgTypeSwitchWeapon:
-		c.handleSwitchWeapon(msg.Payload)
+	if msg.Type == MsgTypeProbe {
+		c.handleProbe(msg.Payload)
+		return
 	}
-}
 
-func (c *Client) handleInput(payload interface{}) {
-	data, _ := json.Marshal(payload)
-	var input InputPayload
-	if err := json.Unmarshal(data, &input); err != nil {
+	newEffect, ok := game.EffectRegistry[msg.Type]
+	if !ok {
 		return
 	}
 
-	c.Lobby.Game.HandleInput(game.PlayerInput{
-		PlayerID: c.ID,
-		Action:   "input",
-		Input: game.InputState{
-			Up:     input.Up,
-			Down:   input.Down,
-			Left:   input.Left,
-			Right:  input.Right,
-			MouseX: input.MouseX,
-			MouseY: input.MouseY,
-			Firing: input.Firing,
-		},
-	})
-}
+	if c.Spectator {
+		log.Printf("Ignoring %s from spectator %s", msg.Type, c.ID)
+		return
+	}
 
-func (c *Client) handleFire() {
-	c.Lobby.Game.HandleInput(game.PlayerInput{
-		PlayerID: c.ID,
-		Action:   "fire",
-	})
+	payload, _ := json.Marshal(msg.Payload)
+	effect := newEffect()
+	if err := json.Unmarshal(payload, effect); err != nil {
+		log.Printf("Failed to parse %s payload: %v", msg.Type, err)
+		return
+	}
+
+	// A move only counts as activity if it actually represents a held key
+	// or fire intent; every other effect is activity by definition. Idle
+	// clients still stream pong frames and shouldn't look active from those.
+	active := true
+	if a, ok := effect.(interface{ IsActivity() bool }); ok {
+		active = a.IsActivity()
+	}
+	if active {
+		c.touch()
+	}
+
+	c.Lobby.HandleEffect(c.ID, effect)
 }
 
-func (c *Client) handleSwitchWeapon(payload interface{}) {
-	data, _ := json.Marshal(payload)
-	var wp SwitchWeaponPayload
-	if err := json.Unmarshal(data, &wp); err != nil {
+// handleProbe answers a MsgTypeProbe query with the nearest obstacle or
+// enemy tank along the ray from the caller's selected tank to the
+// requested point, or a nil Hit if the ray is unobstructed. A probe is a
+// read-only query, so unlike an Effect it's answered immediately rather
+// than enqueued for the next tick.
+func (c *Client) handleProbe(rawPayload interface{}) {
+	if c.Spectator {
+		log.Printf("Ignoring probe from spectator %s", c.ID)
 		return
 	}
 
-	c.Lobby.Game.HandleInput(game.PlayerInput{
-		PlayerID: c.ID,
-		Action:   "switch_weapon",
-		Weapon:   wp.Weapon,
+	payload, _ := json.Marshal(rawPayload)
+	var req ProbePayload
+	if err := json.Unmarshal(payload, &req); err != nil {
+		log.Printf("Failed to parse probe payload: %v", err)
+		return
+	}
+
+	hit := c.Lobby.Game.Probe(c.ID, req.Probe)
+	c.SendMessage(ServerMessage{
+		Type:    MsgTypeProbeResult,
+		Payload: ProbeResultPayload{Hit: hit},
 	})
 }
 
+// touch records that the client took a meaningful game action just now,
+// resetting its idle clock and any pending inactivity warning.
+func (c *Client) touch() {
+	c.actionMu.Lock()
+	defer c.actionMu.Unlock()
+	c.lastActionAt = time.Now()
+	c.warned = false
+}
+
+// IdleFor returns how long it's been since the client's last meaningful
+// action.
+func (c *Client) IdleFor() time.Duration {
+	c.actionMu.Lock()
+	defer c.actionMu.Unlock()
+	return time.Since(c.lastActionAt)
+}
+
+// Warned reports whether the client has already been sent an inactivity
+// warning since its last action.
+func (c *Client) Warned() bool {
+	c.actionMu.Lock()
+	defer c.actionMu.Unlock()
+	return c.warned
+}
+
+// SetWarned records that an inactivity warning has been sent.
+func (c *Client) SetWarned() {
+	c.actionMu.Lock()
+	defer c.actionMu.Unlock()
+	c.warned = true
+}
+
 // SendMessage sends a message to the client
 func (c *Client) SendMessage(msg interface{}) {
 	data, err := json.Marshal(msg)
@@ -189,11 +249,21 @@ func (c *Client) SendMessage(msg interface{}) {
 	case c.Send <- data:
 	default:
 		// Buffer full, close connection
-		close(c.done)
+		c.closeDone()
 	}
 }
 
 // Close terminates the client connection
 func (c *Client) Close() {
-	close(c.done)
+	c.closeDone()
+}
+
+// closeDone closes c.done exactly once. Close and SendMessage's full-buffer
+// branch can both reach here concurrently -- e.g. a lobby resuming this
+// session while ReadPump is mid-handleProbe on the old socket -- so without
+// the guard a second close would panic.
+func (c *Client) closeDone() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+	})
 }
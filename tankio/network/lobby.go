@@ -1,16 +1,30 @@
 package network
 
 import (
+	"crypto/rand"
+	"encoding/binary"
 	"encoding/json"
+	"fmt"
 	"log"
 	"sync"
 	"time"
 
+	"tankio/config"
 	"tankio/game"
+	"tankio/replay"
 )
 
 const (
-	MaxPlayersPerLobby = 2
+	// ReconnectGrace is how long a disconnected player's tank is kept alive
+	// before it's removed, giving a dropped connection time to resume.
+	ReconnectGrace = 30 * time.Second
+
+	// IdleWarnOffset is how long before the kick a warning is sent.
+	IdleWarnOffset = 15 * time.Second
+
+	// MaxSpectatorsPerLobby caps how many read-only viewers a single lobby
+	// will carry, independent of the player cap.
+	MaxSpectatorsPerLobby = 8
 )
 
 // Lobby represents a game room that players can join
@@ -18,6 +32,7 @@ type Lobby struct {
 	Code       string
 	Game       *game.Game
 	Clients    map[string]*Client
+	Spectators map[string]*Client
 	Register   chan *Client
 	Unregister chan *Client
 	Broadcast  chan []byte
@@ -26,19 +41,72 @@ type Lobby struct {
 	lastActivity time.Time
 	closed       bool
 	manager      *LobbyManager
+
+	// sessions maps a session token to the player ID it resumes.
+	sessions map[string]string
+	// pending holds a removal timer for each disconnected player who is
+	// still within their reconnect grace period.
+	pending map[string]*time.Timer
+	// joinOrder records the order players joined in, since that order
+	// determines spawn assignment and must be replayed in the same order
+	// for playback to reproduce it.
+	joinOrder []string
+
+	cfg           config.Config
+	seed          int64
+	tickInterval  time.Duration
+	idleThreshold time.Duration
+	idleWarnAt    time.Duration
+	tickCount     uint64
+
+	// recorder, once the match starts, appends every input and state
+	// snapshot so the match can be replayed later. Nil until then, and nil
+	// entirely for playback lobbies.
+	recorder    *replay.Recorder
+	recordStart time.Time
+
+	// Playback-only fields; set by NewPlaybackLobby, unused otherwise.
+	playback      bool
+	replayClock   *game.ManualClock
+	replayInputs  map[uint64][]recordedEffect
+	replayTick    uint64
+	replayEndTick uint64
 }
 
-// NewLobby creates a new lobby with the given code
-func NewLobby(code string, manager *LobbyManager) *Lobby {
+// recordedEffect pairs a decoded Effect with the player it was submitted by,
+// as read back from a recording's input frames.
+type recordedEffect struct {
+	PlayerID string
+	Effect   game.Effect
+}
+
+// NewLobby creates a new lobby with the given code, using cfg for its tick
+// rate, player cap, idle-kick threshold, and game/weapon settings.
+func NewLobby(code string, manager *LobbyManager, cfg config.Config) *Lobby {
+	idleThreshold := time.Duration(cfg.IdleThresholdSec) * time.Second
+	warnAt := idleThreshold - IdleWarnOffset
+	if warnAt < 0 {
+		warnAt = 0
+	}
+
+	seed := generateSeed()
 	lobby := &Lobby{
-		Code:         code,
-		Game:         game.NewGame(),
-		Clients:      make(map[string]*Client),
-		Register:     make(chan *Client),
-		Unregister:   make(chan *Client),
-		Broadcast:    make(chan []byte, 256),
-		lastActivity: time.Now(),
-		manager:      manager,
+		Code:          code,
+		Game:          game.NewGameDeterministic(cfg, game.RealClock(), game.NewIDGenerator(seed), seed),
+		Clients:       make(map[string]*Client),
+		Spectators:    make(map[string]*Client),
+		Register:      make(chan *Client),
+		Unregister:    make(chan *Client),
+		Broadcast:     make(chan []byte, 256),
+		lastActivity:  time.Now(),
+		manager:       manager,
+		sessions:      make(map[string]string),
+		pending:       make(map[string]*time.Timer),
+		cfg:           cfg,
+		seed:          seed,
+		tickInterval:  time.Duration(cfg.TickMs) * time.Millisecond,
+		idleThreshold: idleThreshold,
+		idleWarnAt:    warnAt,
 	}
 
 	// Set up game broadcast function
@@ -49,8 +117,110 @@ func NewLobby(code string, manager *LobbyManager) *Lobby {
 	return lobby
 }
 
+// NewPlaybackLobby rebuilds the game recorded in rec and returns a Lobby
+// variant that re-simulates it tick-by-tick at the recorded rate instead of
+// reacting to live client input. Connected clients are spectators: their
+// input messages are ignored (registerSpectator marks them Spectator, which
+// Client.handleMessage checks), and they receive the
+// same game_state broadcasts a live match would have sent.
+func NewPlaybackLobby(code string, manager *LobbyManager, rec *replay.Recording) (*Lobby, error) {
+	start, inputs, endTick, err := decodeRecording(rec)
+	if err != nil {
+		return nil, fmt.Errorf("replay: decode recording %s: %w", rec.ID, err)
+	}
+
+	clock := game.NewManualClock(start.StartedAt)
+	g := game.NewGameDeterministic(start.Config, clock, game.NewIDGenerator(start.Seed), start.Seed)
+	for _, id := range start.PlayerOrder {
+		g.AddPlayer(id)
+	}
+	g.SetState(game.Playing)
+
+	lobby := &Lobby{
+		Code:          code,
+		Game:          g,
+		Clients:       make(map[string]*Client),
+		Register:      make(chan *Client),
+		Unregister:    make(chan *Client),
+		Broadcast:     make(chan []byte, 256),
+		lastActivity:  time.Now(),
+		manager:       manager,
+		sessions:      make(map[string]string),
+		pending:       make(map[string]*time.Timer),
+		cfg:           start.Config,
+		seed:          start.Seed,
+		tickInterval:  time.Duration(start.TickMs) * time.Millisecond,
+		playback:      true,
+		replayClock:   clock,
+		replayInputs:  inputs,
+		replayEndTick: endTick,
+	}
+
+	lobby.Game.BroadcastFn = func(msg interface{}) {
+		lobby.BroadcastMessage(msg)
+	}
+
+	return lobby, nil
+}
+
+// decodeRecording splits a recording's frames into its StartFrame and a
+// per-tick index of the effects recorded for it, and reports the highest
+// tick seen so the playback lobby knows when the match ended.
+func decodeRecording(rec *replay.Recording) (replay.StartFrame, map[uint64][]recordedEffect, uint64, error) {
+	var start replay.StartFrame
+	var haveStart bool
+	inputs := make(map[uint64][]recordedEffect)
+	var endTick uint64
+
+	for _, f := range rec.Frames {
+		if f.Tick > endTick {
+			endTick = f.Tick
+		}
+		switch f.Type {
+		case replay.EventStart:
+			if err := json.Unmarshal(f.Data, &start); err != nil {
+				return start, nil, 0, err
+			}
+			haveStart = true
+		case replay.EventInput:
+			var in replay.InputFrame
+			if err := json.Unmarshal(f.Data, &in); err != nil {
+				return start, nil, 0, err
+			}
+			factory, ok := game.EffectRegistry[in.Kind]
+			if !ok {
+				// Recorded by a server version with an effect kind this one
+				// doesn't know; skip it rather than fail the whole replay.
+				continue
+			}
+			effect := factory()
+			if err := json.Unmarshal(in.Payload, effect); err != nil {
+				return start, nil, 0, err
+			}
+			inputs[f.Tick] = append(inputs[f.Tick], recordedEffect{PlayerID: in.PlayerID, Effect: effect})
+		}
+	}
+
+	if !haveStart {
+		return start, nil, 0, fmt.Errorf("recording has no start frame")
+	}
+	return start, inputs, endTick, nil
+}
+
+// generateSeed picks a random starting point for a match's bullet ID
+// generator. It's recorded in the replay's start frame so playback can seed
+// an identical generator and reproduce the same bullet IDs.
+func generateSeed() int64 {
+	var b [8]byte
+	rand.Read(b[:])
+	return int64(binary.BigEndian.Uint64(b[:]))
+}
+
 // Run starts the lobby's main loop
 func (l *Lobby) Run() {
+	ticker := time.NewTicker(l.tickInterval)
+	defer ticker.Stop()
+
 	for {
 		select {
 		case client := <-l.Register:
@@ -61,6 +231,9 @@ func (l *Lobby) Run() {
 
 		case message := <-l.Broadcast:
 			l.handleBroadcast(message)
+
+		case <-ticker.C:
+			l.tick()
 		}
 
 		// Check if lobby should be closed
@@ -73,11 +246,225 @@ func (l *Lobby) Run() {
 	}
 }
 
+// tick advances the game simulation and broadcasts the resulting state.
+func (l *Lobby) tick() {
+	if l.playback {
+		l.playbackTick()
+		return
+	}
+
+	l.Game.Update(l.cfg.Delta())
+	snap := l.Game.GetSnapshot()
+
+	l.mu.Lock()
+	l.tickCount++
+	tick := l.tickCount
+	l.mu.Unlock()
+	if l.recorder != nil {
+		l.recorder.RecordSnapshot(tick, snap)
+	}
+
+	l.broadcastGameState(tick)
+
+	if snap.State == game.Playing {
+		l.checkIdleClients()
+	}
+}
+
+// broadcastGameState sends every connected client and spectator its own
+// fog-of-war-filtered view of the match: full detail for its own tank,
+// truncated detail for any other tank within scanner range and line of
+// sight, and nothing for tanks it can't see. Spectators aren't attached to
+// a tank, so they see everything, same as before this existed. turn is the
+// monotonically increasing tick this state was computed on, so a client can
+// tell whether it dropped a frame and needs to interpolate further to catch
+// up rather than assuming every game_state arrived back-to-back.
+func (l *Lobby) broadcastGameState(turn uint64) {
+	l.mu.RLock()
+	recipients := make([]*Client, 0, len(l.Clients)+len(l.Spectators))
+	for _, c := range l.Clients {
+		recipients = append(recipients, c)
+	}
+	for _, c := range l.Spectators {
+		recipients = append(recipients, c)
+	}
+	l.mu.RUnlock()
+
+	for _, c := range recipients {
+		view := l.Game.GetVisibleSnapshot(c.ID)
+		c.SendMessage(ServerMessage{
+			Type: MsgTypeGameState,
+			Payload: GameStatePayload{
+				Turn:      turn,
+				State:     view.State,
+				Players:   view.Players,
+				Enemies:   view.Enemies,
+				Bullets:   view.Bullets,
+				Splosions: view.Splosions,
+			},
+		})
+	}
+}
+
+// playbackTick replays one recorded tick: it feeds in whatever inputs were
+// recorded for this tick, advances the replay clock by the recorded tick
+// rate, and broadcasts the resulting state to connected spectators. Once
+// the recording is exhausted, it removes itself from the manager.
+func (l *Lobby) playbackTick() {
+	l.replayTick++
+	l.replayClock.Advance(l.tickInterval)
+
+	for _, re := range l.replayInputs[l.replayTick] {
+		l.Game.Enqueue(re.PlayerID, re.Effect)
+	}
+	l.Game.Update(l.cfg.Delta())
+
+	l.broadcastGameState(l.replayTick)
+
+	if l.replayTick >= l.replayEndTick {
+		l.BroadcastMessage(ServerMessage{
+			Type:    MsgTypeReplayDone,
+			Payload: ReplayDonePayload{LobbyCode: l.Code},
+		})
+		l.manager.RemoveLobby(l.Code)
+	}
+}
+
+// HandleEffect records e (if this lobby is recording a match) and enqueues
+// it to be applied on the game's next tick.
+func (l *Lobby) HandleEffect(playerID string, e game.Effect) {
+	if l.recorder != nil {
+		if payload, err := json.Marshal(e); err == nil {
+			l.recorder.RecordInput(l.currentTick(), playerID, e.Kind(), payload)
+		}
+	}
+	l.Game.Enqueue(playerID, e)
+}
+
+// IsPlayback reports whether this lobby is re-simulating a recorded match
+// rather than running a live one; connected clients are spectators whose
+// input messages should be ignored.
+func (l *Lobby) IsPlayback() bool {
+	return l.playback
+}
+
+// currentTick returns the tick number in-flight inputs should be recorded
+// against.
+func (l *Lobby) currentTick() uint64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.tickCount + 1
+}
+
+// startRecording begins recording the match once it starts Playing. Caller
+// must hold l.mu.
+func (l *Lobby) startRecording() {
+	startedAt := time.Now()
+	rec, err := replay.NewRecorder(l.manager.replayDir(), l.Code, startedAt)
+	if err != nil {
+		log.Printf("replay: could not start recording for lobby %s: %v", l.Code, err)
+		return
+	}
+
+	l.recorder = rec
+	l.recordStart = startedAt
+	rec.RecordStart(replay.StartFrame{
+		Config:      l.cfg,
+		Seed:        l.seed,
+		TickMs:      l.cfg.TickMs,
+		PlayerOrder: append([]string(nil), l.joinOrder...),
+		StartedAt:   startedAt,
+	})
+}
+
+// sendMapInit sends a client the arena layout, once, right after it's
+// confirmed its connection. The obstacle list never changes after
+// construction, so there's nothing to re-send on later state syncs.
+func (l *Lobby) sendMapInit(client *Client) {
+	client.SendMessage(ServerMessage{
+		Type: MsgTypeMapInit,
+		Payload: MapInitPayload{
+			Seed:      l.seed,
+			Obstacles: l.Game.Obstacles(),
+		},
+	})
+}
+
+// checkIdleClients warns or evicts players who haven't taken a meaningful
+// action in too long, so an AFK tank doesn't hold a lobby slot hostage.
+func (l *Lobby) checkIdleClients() {
+	l.mu.RLock()
+	clients := make([]*Client, 0, len(l.Clients))
+	for _, c := range l.Clients {
+		clients = append(clients, c)
+	}
+	l.mu.RUnlock()
+
+	for _, c := range clients {
+		idle := c.IdleFor()
+		switch {
+		case idle >= l.idleThreshold:
+			l.kickIdleClient(c)
+		case idle >= l.idleWarnAt && !c.Warned():
+			c.SetWarned()
+			c.SendMessage(ServerMessage{
+				Type: MsgTypeKicked,
+				Payload: KickWarningPayload{
+					Message:          "you will be kicked for inactivity",
+					SecondsRemaining: (l.idleThreshold - idle).Seconds(),
+				},
+			})
+		}
+	}
+}
+
+// kickIdleClient evicts a single idle player and lets the survivors know.
+func (l *Lobby) kickIdleClient(c *Client) {
+	l.Game.KillPlayer(c.ID)
+
+	c.SendMessage(ServerMessage{
+		Type:    MsgTypeError,
+		Payload: ErrorPayload{Message: "kicked for inactivity"},
+	})
+	l.BroadcastMessage(ServerMessage{
+		Type:    MsgTypePlayerKicked,
+		Payload: PlayerKickedPayload{PlayerID: c.ID, Reason: "inactivity"},
+	})
+
+	log.Printf("Player %s kicked from lobby %s for inactivity", c.ID, l.Code)
+	c.Close()
+}
+
+// PlayerIDForToken returns the player ID bound to a session token, if that
+// player is still known to the lobby (connected, or within its grace
+// period after a disconnect).
+func (l *Lobby) PlayerIDForToken(token string) (string, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	id, ok := l.sessions[token]
+	return id, ok
+}
+
 func (l *Lobby) handleRegister(client *Client) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
-	if len(l.Clients) >= MaxPlayersPerLobby {
+	if l.playback {
+		l.registerSpectator(client)
+		return
+	}
+
+	if client.Spectator {
+		l.addSpectator(client)
+		return
+	}
+
+	if client.Resumed {
+		l.resumeClient(client)
+		return
+	}
+
+	if len(l.Clients) >= l.cfg.MaxPlayersPerLobby {
 		client.SendMessage(ServerMessage{
 			Type:    MsgTypeError,
 			Payload: ErrorPayload{Message: "Lobby is full"},
@@ -87,47 +474,234 @@ func (l *Lobby) handleRegister(client *Client) {
 	}
 
 	l.Clients[client.ID] = client
+	l.sessions[client.SessionToken] = client.ID
+	l.joinOrder = append(l.joinOrder, client.ID)
 	l.lastActivity = time.Now()
 
 	// Add player to game
-	l.Game.AddPlayer(client.ID)
+	player := l.Game.AddPlayer(client.ID)
+	if l.Game.State() == game.Playing && l.recorder == nil {
+		l.startRecording()
+	}
 
 	// Send connection confirmation
 	client.SendMessage(ServerMessage{
 		Type: MsgTypeConnected,
 		Payload: ConnectedPayload{
-			PlayerID:  client.ID,
-			LobbyCode: l.Code,
+			PlayerID:     client.ID,
+			LobbyCode:    l.Code,
+			SessionToken: client.SessionToken,
+			TankIDs:      player.TankIDs(),
 		},
 	})
+	l.sendMapInit(client)
 
 	// Send lobby info to all players
 	l.broadcastLobbyInfo()
 
 	log.Printf("Player %s joined lobby %s (%d/%d players)",
-		client.ID, l.Code, len(l.Clients), MaxPlayersPerLobby)
+		client.ID, l.Code, len(l.Clients), l.cfg.MaxPlayersPerLobby)
+}
+
+// addSpectator attaches a client to a live lobby as a read-only viewer: it
+// counts against MaxSpectatorsPerLobby instead of the player cap, is never
+// passed to Game.AddPlayer, and gets every broadcast a player does through
+// the same Send channel machinery. Caller must hold l.mu.
+func (l *Lobby) addSpectator(client *Client) {
+	if len(l.Spectators) >= MaxSpectatorsPerLobby {
+		client.SendMessage(ServerMessage{
+			Type:    MsgTypeError,
+			Payload: ErrorPayload{Message: "Too many spectators"},
+		})
+		client.Close()
+		return
+	}
+
+	l.Spectators[client.ID] = client
+	l.lastActivity = time.Now()
+
+	client.SendMessage(ServerMessage{
+		Type: MsgTypeConnected,
+		Payload: ConnectedPayload{
+			PlayerID:  client.ID,
+			LobbyCode: l.Code,
+		},
+	})
+	l.sendMapInit(client)
+
+	snap := l.Game.GetSnapshot()
+	client.SendMessage(ServerMessage{
+		Type: MsgTypeStateSync,
+		Payload: GameStatePayload{
+			Turn:      l.tickCount,
+			State:     snap.State,
+			Players:   snap.Players,
+			Bullets:   snap.Bullets,
+			Splosions: snap.Splosions,
+		},
+	})
+
+	l.broadcastLobbyInfo()
+	l.BroadcastMessage(ServerMessage{
+		Type:    MsgTypeSpectatorJoined,
+		Payload: SpectatorJoinedPayload{SpectatorID: client.ID},
+	})
+
+	log.Printf("Spectator %s joined lobby %s (%d/%d spectators)",
+		client.ID, l.Code, len(l.Spectators), MaxSpectatorsPerLobby)
+}
+
+// registerSpectator attaches a client to a playback lobby as a read-only
+// viewer: no tank, no session bookkeeping, just a snapshot to start from and
+// the same game_state broadcasts everyone else gets. Caller must hold l.mu.
+func (l *Lobby) registerSpectator(client *Client) {
+	client.Spectator = true
+	l.Clients[client.ID] = client
+	l.lastActivity = time.Now()
+
+	client.SendMessage(ServerMessage{
+		Type: MsgTypeConnected,
+		Payload: ConnectedPayload{
+			PlayerID:  client.ID,
+			LobbyCode: l.Code,
+		},
+	})
+	l.sendMapInit(client)
+
+	snap := l.Game.GetSnapshot()
+	client.SendMessage(ServerMessage{
+		Type: MsgTypeStateSync,
+		Payload: GameStatePayload{
+			Turn:      l.tickCount,
+			State:     snap.State,
+			Players:   snap.Players,
+			Bullets:   snap.Bullets,
+			Splosions: snap.Splosions,
+		},
+	})
+
+	log.Printf("Spectator %s joined playback lobby %s", client.ID, l.Code)
+}
+
+// resumeClient attaches a reconnecting socket to an existing player slot and
+// replays a snapshot of the match so far. Caller must hold l.mu.
+func (l *Lobby) resumeClient(client *Client) {
+	if timer, ok := l.pending[client.ID]; ok {
+		timer.Stop()
+		delete(l.pending, client.ID)
+	}
+
+	// A second socket for the same session (e.g. a browser refresh) bumps
+	// the old one out cleanly rather than racing it.
+	if old, ok := l.Clients[client.ID]; ok && old != client {
+		old.Close()
+	}
+
+	l.Clients[client.ID] = client
+	l.lastActivity = time.Now()
+
+	resumed := true
+	player, ok := l.Game.GetPlayer(client.ID)
+	if !ok {
+		// finalizeRemoval's grace timer won the race against this resume
+		// and already tore down the fleet (see
+		// TestLobbyFinalizeRemovalWinsRaceAgainstResume): there's nothing
+		// left to resume, so spawn a fresh fleet under the same player ID
+		// instead of claiming a resume that didn't happen.
+		player = l.Game.AddPlayer(client.ID)
+		l.sessions[client.SessionToken] = client.ID
+		resumed = false
+	}
+	tankIDs := player.TankIDs()
+
+	client.SendMessage(ServerMessage{
+		Type: MsgTypeConnected,
+		Payload: ConnectedPayload{
+			PlayerID:     client.ID,
+			LobbyCode:    l.Code,
+			SessionToken: client.SessionToken,
+			Resumed:      resumed,
+			TankIDs:      tankIDs,
+		},
+	})
+	l.sendMapInit(client)
+
+	snap := l.Game.GetSnapshot()
+	client.SendMessage(ServerMessage{
+		Type: MsgTypeStateSync,
+		Payload: GameStatePayload{
+			Turn:      l.tickCount,
+			State:     snap.State,
+			Players:   snap.Players,
+			Bullets:   snap.Bullets,
+			Splosions: snap.Splosions,
+		},
+	})
+
+	l.broadcastLobbyInfo()
+	log.Printf("Player %s resumed lobby %s", client.ID, l.Code)
 }
 
 func (l *Lobby) handleUnregister(client *Client) {
 	l.mu.Lock()
-	defer l.mu.Unlock()
 
-	if _, ok := l.Clients[client.ID]; ok {
-		delete(l.Clients, client.ID)
+	if current, ok := l.Spectators[client.ID]; ok && current == client {
+		delete(l.Spectators, client.ID)
 		close(client.Send)
-		l.Game.RemovePlayer(client.ID)
 		l.lastActivity = time.Now()
+		log.Printf("Spectator %s left lobby %s", client.ID, l.Code)
+		l.broadcastLobbyInfo()
+		l.mu.Unlock()
+		return
+	}
 
-		log.Printf("Player %s left lobby %s", client.ID, l.Code)
+	current, ok := l.Clients[client.ID]
+	if !ok || current != client {
+		// Already superseded by a resumed connection; nothing to do.
+		l.mu.Unlock()
+		return
+	}
+
+	delete(l.Clients, client.ID)
+	close(client.Send)
+	l.lastActivity = time.Now()
 
-		// If no players left, mark lobby for cleanup
-		if len(l.Clients) == 0 {
-			l.closed = true
-			l.manager.RemoveLobby(l.Code)
-		} else {
-			l.broadcastLobbyInfo()
+	playerID := client.ID
+	l.pending[playerID] = time.AfterFunc(ReconnectGrace, func() {
+		l.finalizeRemoval(playerID)
+	})
+
+	log.Printf("Player %s disconnected from lobby %s, grace period started", client.ID, l.Code)
+	l.broadcastLobbyInfo()
+	l.mu.Unlock()
+}
+
+// finalizeRemoval drops a disconnected player's tank once its reconnect
+// grace period has elapsed without a resume.
+func (l *Lobby) finalizeRemoval(playerID string) {
+	l.mu.Lock()
+	if _, ok := l.pending[playerID]; !ok {
+		// Reconnected in the meantime; the timer was stopped too late to
+		// prevent this call, so just bail out.
+		l.mu.Unlock()
+		return
+	}
+	delete(l.pending, playerID)
+	for token, id := range l.sessions {
+		if id == playerID {
+			delete(l.sessions, token)
 		}
 	}
+	l.Game.RemovePlayer(playerID)
+
+	log.Printf("Player %s's grace period expired in lobby %s, tank removed", playerID, l.Code)
+	l.broadcastLobbyInfo()
+	l.mu.Unlock()
+
+	// The emptiness check has to happen again inside RemoveLobbyIfEmpty,
+	// under l.mu, since a Register can land in the gap between the unlock
+	// above and the manager acting on it.
+	l.manager.RemoveLobbyIfEmpty(l.Code)
 }
 
 func (l *Lobby) handleBroadcast(message []byte) {
@@ -141,6 +715,13 @@ func (l *Lobby) handleBroadcast(message []byte) {
 			// Client buffer full, will be cleaned up
 		}
 	}
+	for _, client := range l.Spectators {
+		select {
+		case client.Send <- message:
+		default:
+			// Client buffer full, will be cleaned up
+		}
+	}
 }
 
 // BroadcastMessage sends a message to all clients
@@ -166,11 +747,14 @@ func (l *Lobby) broadcastLobbyInfo() {
 	l.BroadcastMessage(ServerMessage{
 		Type: MsgTypeLobbyInfo,
 		Payload: LobbyInfoPayload{
-			Code:        l.Code,
-			PlayerCount: len(l.Clients),
-			MaxPlayers:  MaxPlayersPerLobby,
-			State:       string(l.Game.State),
-			Players:     players,
+			Code:           l.Code,
+			PlayerCount:    len(l.Clients),
+			MaxPlayers:     l.cfg.MaxPlayersPerLobby,
+			SpectatorCount: len(l.Spectators),
+			State:          string(l.Game.State()),
+			Players:        players,
+			TanksPerPlayer: l.cfg.TanksPerPlayer,
+			Mode:           l.cfg.Mode,
 		},
 	})
 }
@@ -182,6 +766,18 @@ func (l *Lobby) GetPlayerCount() int {
 	return len(l.Clients)
 }
 
+// SpectatorCount returns the number of connected spectators
+func (l *Lobby) SpectatorCount() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return len(l.Spectators)
+}
+
+// MaxPlayers returns this lobby's configured player cap.
+func (l *Lobby) MaxPlayers() int {
+	return l.cfg.MaxPlayersPerLobby
+}
+
 // LastActivity returns when the lobby was last active
 func (l *Lobby) LastActivity() time.Time {
 	l.mu.RLock()
@@ -193,7 +789,28 @@ func (l *Lobby) LastActivity() time.Time {
 func (l *Lobby) Close() {
 	l.mu.Lock()
 	defer l.mu.Unlock()
+	l.closeLocked()
+}
+
+// closeIfEmpty closes the lobby and reports true, but only if it still has
+// no clients and no pending reconnects at the moment the lock is acquired.
+// Re-checking here, rather than trusting a staleness-prone bool the caller
+// computed before releasing l.mu, is what keeps a Register that lands in
+// that gap from being force-disconnected by a close that's no longer
+// warranted. Caller must not hold l.mu.
+func (l *Lobby) closeIfEmpty() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.Clients) != 0 || len(l.pending) != 0 {
+		return false
+	}
+	l.closeLocked()
+	return true
+}
 
+// closeLocked runs the actual teardown. Caller must hold l.mu.
+func (l *Lobby) closeLocked() {
 	if l.closed {
 		return
 	}
@@ -202,8 +819,25 @@ func (l *Lobby) Close() {
 	// Stop the game
 	l.Game.Stop()
 
+	// Cancel any pending reconnect-grace removals
+	for playerID, timer := range l.pending {
+		timer.Stop()
+		delete(l.pending, playerID)
+	}
+
 	// Close all client connections
 	for _, client := range l.Clients {
 		client.Close()
 	}
+	for _, client := range l.Spectators {
+		client.Close()
+	}
+
+	// Finalize the match recording, if one was started, so it shows up in
+	// the replay store once the lobby is gone.
+	if l.recorder != nil {
+		l.recorder.Close()
+		l.manager.finishRecording(l.Code, l.recordStart, l.recorder.Frames())
+		l.recorder = nil
+	}
 }
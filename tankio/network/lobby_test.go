@@ -0,0 +1,87 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"tankio/config"
+)
+
+// newRaceTestLobby creates a lobby with two players, p1 and p2. p2 stays
+// registered as a live client throughout so the lobby's own bookkeeping
+// (e.g. finalizeRemoval's empty-lobby check) never needs a manager to act
+// on, keeping these tests focused on the p1 resume-vs-grace-timer race.
+func newRaceTestLobby(t *testing.T) *Lobby {
+	t.Helper()
+	cfg := config.Default()
+	manager := NewLobbyManager(cfg, "")
+	lobby := NewLobby("TEST", manager, cfg)
+
+	lobby.Game.AddPlayer("p1")
+	lobby.Game.AddPlayer("p2")
+	lobby.Clients["p2"] = NewClient("p2", "tok2", nil, lobby)
+
+	return lobby
+}
+
+// TestLobbyResumeWinsRaceAgainstGraceTimer covers the interleaving where a
+// reconnect reaches resumeClient before its stale grace-period timer's
+// finalizeRemoval callback acquires l.mu: resumeClient must cancel the
+// pending removal so the later finalizeRemoval call is a no-op rather than
+// evicting the session that just resumed.
+func TestLobbyResumeWinsRaceAgainstGraceTimer(t *testing.T) {
+	lobby := newRaceTestLobby(t)
+	lobby.pending["p1"] = time.AfterFunc(time.Hour, func() {})
+
+	client := NewClient("p1", "tok1", nil, lobby)
+	lobby.resumeClient(client)
+
+	if _, ok := lobby.pending["p1"]; ok {
+		t.Fatalf("resumeClient left a pending removal timer for the session it just resumed")
+	}
+
+	// Simulate the timer's callback, already in flight when resumeClient
+	// ran, finally acquiring the lock.
+	lobby.finalizeRemoval("p1")
+
+	if _, ok := lobby.Game.GetPlayer("p1"); !ok {
+		t.Fatalf("finalizeRemoval evicted a player whose reconnect had already cancelled the grace timer")
+	}
+	if current := lobby.Clients["p1"]; current != client {
+		t.Fatalf("resumed client was replaced by the losing finalizeRemoval call")
+	}
+}
+
+// TestLobbyFinalizeRemovalWinsRaceAgainstResume covers the opposite
+// interleaving: the grace timer's finalizeRemoval acquires l.mu first and
+// actually evicts the player before a racing reconnect reaches
+// resumeClient. resumeClient must give the reconnecting client a working
+// fleet again rather than a "resumed" connection with no tanks to play.
+func TestLobbyFinalizeRemovalWinsRaceAgainstResume(t *testing.T) {
+	lobby := newRaceTestLobby(t)
+	lobby.pending["p1"] = time.AfterFunc(time.Hour, func() {})
+
+	lobby.finalizeRemoval("p1")
+
+	if _, ok := lobby.Game.GetPlayer("p1"); ok {
+		t.Fatalf("finalizeRemoval did not remove the player")
+	}
+
+	client := NewClient("p1", "tok1", nil, lobby)
+	lobby.resumeClient(client)
+
+	if current := lobby.Clients["p1"]; current != client {
+		t.Fatalf("resumeClient did not register the reconnecting client")
+	}
+
+	player, ok := lobby.Game.GetPlayer("p1")
+	if !ok {
+		t.Fatalf("resumeClient left the player without a fleet after finalizeRemoval won the race")
+	}
+	if len(player.TankIDs()) == 0 {
+		t.Fatalf("resumeClient gave the player an empty fleet")
+	}
+	if got, want := lobby.sessions[client.SessionToken], "p1"; got != want {
+		t.Fatalf("resumeClient did not re-register the session token: got %q, want %q", got, want)
+	}
+}
@@ -3,22 +3,32 @@ package network
 import (
 	"crypto/rand"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
+	"tankio/config"
+	"tankio/replay"
 )
 
 const (
-	MaxLobbies       = 5
-	LobbyTimeout     = 30 * time.Minute
-	CleanupInterval  = 1 * time.Minute
-	LobbyCodeLength  = 4
+	CleanupInterval = 1 * time.Minute
+	LobbyCodeLength = 4
 )
 
+// ErrMaxLobbies is returned by CreateLobby when the server is already
+// hosting its configured maximum number of concurrent lobbies.
+var ErrMaxLobbies = errors.New("maximum number of lobbies reached")
+
+// ErrReplayNotFound is returned when a replay ID doesn't match any recorded
+// match.
+var ErrReplayNotFound = errors.New("replay not found")
+
 var upgrader = websocket.Upgrader{
 	ReadBufferSize:  1024,
 	WriteBufferSize: 1024,
@@ -29,24 +39,50 @@ var upgrader = websocket.Upgrader{
 
 // LobbyManager manages all active game lobbies
 type LobbyManager struct {
+	cfg     config.Config
 	lobbies map[string]*Lobby
 	mu      sync.RWMutex
+
+	replays *replay.Store
 }
 
-// NewLobbyManager creates a new lobby manager
-func NewLobbyManager() *LobbyManager {
+// NewLobbyManager creates a new lobby manager using cfg as the base
+// settings every lobby starts from, before any per-lobby overrides.
+// replayDir is where finished matches are persisted as frame files; pass ""
+// to keep recordings in memory only.
+func NewLobbyManager(cfg config.Config, replayDir string) *LobbyManager {
 	return &LobbyManager{
+		cfg:     cfg,
 		lobbies: make(map[string]*Lobby),
+		replays: replay.NewStore(replayDir),
 	}
 }
 
-// CreateLobby creates a new lobby and returns its code
-func (lm *LobbyManager) CreateLobby() (*Lobby, error) {
+// replayDir returns the directory recordings should be written under.
+func (lm *LobbyManager) replayDir() string {
+	return lm.replays.Dir()
+}
+
+// finishRecording indexes a lobby's finished recording in the replay store.
+func (lm *LobbyManager) finishRecording(lobbyCode string, startedAt time.Time, frames []replay.Frame) {
+	rec := lm.replays.Add(lobbyCode, startedAt, frames)
+	log.Printf("Recorded match %s for lobby %s (%d frames)", rec.ID, lobbyCode, len(frames))
+}
+
+// CreateLobby creates a new lobby and returns its code. overrides is merged
+// onto the manager's base config (see config.Config.WithOverrides); pass
+// config.Config{} to use the base settings unchanged.
+func (lm *LobbyManager) CreateLobby(overrides config.Config) (*Lobby, error) {
+	cfg := lm.cfg.WithOverrides(overrides)
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid lobby settings: %w", err)
+	}
+
 	lm.mu.Lock()
 	defer lm.mu.Unlock()
 
-	if len(lm.lobbies) >= MaxLobbies {
-		return nil, fmt.Errorf("maximum number of lobbies reached (%d)", MaxLobbies)
+	if len(lm.lobbies) >= lm.cfg.MaxLobbies {
+		return nil, fmt.Errorf("%w (%d)", ErrMaxLobbies, lm.cfg.MaxLobbies)
 	}
 
 	// Generate unique code
@@ -58,13 +94,13 @@ func (lm *LobbyManager) CreateLobby() (*Lobby, error) {
 		}
 	}
 
-	lobby := NewLobby(code, lm)
+	lobby := NewLobby(code, lm, cfg)
 	lm.lobbies[code] = lobby
 
 	// Start lobby goroutine
 	go lobby.Run()
 
-	log.Printf("Created lobby %s (%d/%d active)", code, len(lm.lobbies), MaxLobbies)
+	log.Printf("Created lobby %s (%d/%d active)", code, len(lm.lobbies), lm.cfg.MaxLobbies)
 	return lobby, nil
 }
 
@@ -75,7 +111,10 @@ func (lm *LobbyManager) GetLobby(code string) *Lobby {
 	return lm.lobbies[code]
 }
 
-// RemoveLobby removes a lobby from the manager
+// RemoveLobby removes a lobby from the manager unconditionally. Use this
+// for deliberate, terminal teardowns (e.g. a finished replay lobby); for a
+// removal that's only supposed to happen if the lobby is still empty, use
+// RemoveLobbyIfEmpty instead.
 func (lm *LobbyManager) RemoveLobby(code string) {
 	lm.mu.Lock()
 	defer lm.mu.Unlock()
@@ -83,8 +122,25 @@ func (lm *LobbyManager) RemoveLobby(code string) {
 	if lobby, exists := lm.lobbies[code]; exists {
 		lobby.Close()
 		delete(lm.lobbies, code)
-		log.Printf("Removed lobby %s (%d/%d active)", code, len(lm.lobbies), MaxLobbies)
+		log.Printf("Removed lobby %s (%d/%d active)", code, len(lm.lobbies), lm.cfg.MaxLobbies)
+	}
+}
+
+// RemoveLobbyIfEmpty removes code's lobby only if it is still empty at the
+// moment this runs, re-checking under the lobby's own lock rather than
+// trusting an emptiness flag the caller computed before releasing it. This
+// closes the window where a Register lands between a caller's own "are we
+// empty" check and this call actually tearing the lobby down.
+func (lm *LobbyManager) RemoveLobbyIfEmpty(code string) {
+	lm.mu.Lock()
+	defer lm.mu.Unlock()
+
+	lobby, exists := lm.lobbies[code]
+	if !exists || !lobby.closeIfEmpty() {
+		return
 	}
+	delete(lm.lobbies, code)
+	log.Printf("Removed lobby %s (%d/%d active)", code, len(lm.lobbies), lm.cfg.MaxLobbies)
 }
 
 // CleanupRoutine periodically removes inactive lobbies
@@ -101,9 +157,10 @@ func (lm *LobbyManager) cleanupInactiveLobbies() {
 	lm.mu.Lock()
 	defer lm.mu.Unlock()
 
+	timeout := time.Duration(lm.cfg.LobbyTimeoutSec) * time.Second
 	now := time.Now()
 	for code, lobby := range lm.lobbies {
-		if now.Sub(lobby.LastActivity()) > LobbyTimeout {
+		if now.Sub(lobby.LastActivity()) > timeout {
 			lobby.Close()
 			delete(lm.lobbies, code)
 			log.Printf("Cleaned up inactive lobby %s", code)
@@ -111,16 +168,30 @@ func (lm *LobbyManager) cleanupInactiveLobbies() {
 	}
 }
 
-// HandleCreateLobby handles the POST /api/create-lobby endpoint
+// HandleCreateLobby handles the POST /api/create-lobby endpoint. The body,
+// if present, is a JSON config.Config fragment: any field it sets overrides
+// the server's base settings for this lobby alone (see config.Config.WithOverrides).
 func (lm *LobbyManager) HandleCreateLobby(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	lobby, err := lm.CreateLobby()
+	var overrides config.Config
+	if r.Body != nil && r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&overrides); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	lobby, err := lm.CreateLobby(overrides)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		status := http.StatusBadRequest
+		if errors.Is(err, ErrMaxLobbies) {
+			status = http.StatusServiceUnavailable
+		}
+		http.Error(w, err.Error(), status)
 		return
 	}
 
@@ -143,9 +214,10 @@ func (lm *LobbyManager) HandleListLobbies(w http.ResponseWriter, r *http.Request
 	lobbies := make([]map[string]interface{}, 0)
 	for code, lobby := range lm.lobbies {
 		lobbies = append(lobbies, map[string]interface{}{
-			"code":        code,
-			"playerCount": lobby.GetPlayerCount(),
-			"maxPlayers":  MaxPlayersPerLobby,
+			"code":           code,
+			"playerCount":    lobby.GetPlayerCount(),
+			"maxPlayers":     lobby.MaxPlayers(),
+			"spectatorCount": lobby.SpectatorCount(),
 		})
 	}
 
@@ -153,7 +225,92 @@ func (lm *LobbyManager) HandleListLobbies(w http.ResponseWriter, r *http.Request
 	json.NewEncoder(w).Encode(lobbies)
 }
 
-// HandleWebSocket handles WebSocket connections
+// HandleListReplays handles the GET /api/replays endpoint.
+func (lm *LobbyManager) HandleListReplays(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	recs := lm.replays.List()
+	out := make([]map[string]interface{}, 0, len(recs))
+	for _, rec := range recs {
+		out = append(out, map[string]interface{}{
+			"id":        rec.ID,
+			"lobbyCode": rec.LobbyCode,
+			"startedAt": rec.StartedAt,
+			"frames":    len(rec.Frames),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(out)
+}
+
+// HandleGetReplay handles the GET /api/replays/{id} endpoint.
+func (lm *LobbyManager) HandleGetReplay(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/api/replays/")
+	rec, ok := lm.replays.Get(id)
+	if !ok {
+		http.Error(w, ErrReplayNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rec)
+}
+
+// HandlePlayReplay handles the POST /api/replays/{id}/play endpoint. It
+// spins up a playback lobby re-simulating the recorded match and returns its
+// lobby code, which spectators then connect to over the usual WebSocket
+// endpoint.
+func (lm *LobbyManager) HandlePlayReplay(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/replays/"), "/play")
+	rec, ok := lm.replays.Get(id)
+	if !ok {
+		http.Error(w, ErrReplayNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	lm.mu.Lock()
+	var code string
+	for {
+		code = generateLobbyCode()
+		if _, exists := lm.lobbies[code]; !exists {
+			break
+		}
+	}
+
+	lobby, err := NewPlaybackLobby(code, lm, rec)
+	if err != nil {
+		lm.mu.Unlock()
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	lm.lobbies[code] = lobby
+	lm.mu.Unlock()
+
+	go lobby.Run()
+
+	log.Printf("Started playback lobby %s for replay %s", code, id)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"code": lobby.Code,
+	})
+}
+
+// HandleWebSocket handles WebSocket connections. Pass ?role=spectator to
+// join as a read-only viewer instead of a player.
 func (lm *LobbyManager) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 	lobbyCode := r.URL.Query().Get("lobby")
 	if lobbyCode == "" {
@@ -167,16 +324,29 @@ func (lm *LobbyManager) HandleWebSocket(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// A returning client presents the session token it was issued on first
+	// join so it can resume its tank instead of starting a fresh one.
+	playerID, resumed := "", false
+	token := r.URL.Query().Get("token")
+	if token != "" {
+		if pid, ok := lobby.PlayerIDForToken(token); ok {
+			playerID, resumed = pid, true
+		}
+	}
+	if playerID == "" {
+		playerID = generatePlayerID()
+		token = generateSessionToken()
+	}
+
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Printf("WebSocket upgrade failed: %v", err)
 		return
 	}
 
-	// Generate player ID
-	playerID := generatePlayerID()
-
-	client := NewClient(playerID, conn, lobby)
+	client := NewClient(playerID, token, conn, lobby)
+	client.Resumed = resumed
+	client.Spectator = r.URL.Query().Get("role") == "spectator"
 
 	// Register client with lobby
 	lobby.Register <- client
@@ -203,3 +373,11 @@ func generatePlayerID() string {
 	rand.Read(b)
 	return fmt.Sprintf("P%X", b)
 }
+
+// generateSessionToken creates an opaque token a client can later present to
+// resume its player slot after a dropped connection.
+func generateSessionToken() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return fmt.Sprintf("%X", b)
+}
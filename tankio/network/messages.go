@@ -4,35 +4,15 @@ import (
 	"tankio/game"
 )
 
-// ClientMessage represents a message from client to server
+// ClientMessage represents a message from client to server. Type is looked
+// up in game.EffectRegistry to find the concrete Effect to unmarshal
+// Payload into; see that registry for the set of valid types and their
+// JSON schemas.
 type ClientMessage struct {
 	Type    string      `json:"type"`
 	Payload interface{} `json:"payload,omitempty"`
 }
 
-// Message types from client
-const (
-	MsgTypeInput        = "input"         // Player input state
-	MsgTypeFire         = "fire"          // Fire weapon
-	MsgTypeSwitchWeapon = "switch_weapon" // Change weapon
-)
-
-// InputPayload is the payload for input messages
-type InputPayload struct {
-	Up     bool    `json:"up"`
-	Down   bool    `json:"down"`
-	Left   bool    `json:"left"`
-	Right  bool    `json:"right"`
-	MouseX float64 `json:"mouseX"`
-	MouseY float64 `json:"mouseY"`
-	Firing bool    `json:"firing"`
-}
-
-// SwitchWeaponPayload is the payload for weapon switch messages
-type SwitchWeaponPayload struct {
-	Weapon game.WeaponType `json:"weapon"`
-}
-
 // ServerMessage represents a message from server to client
 type ServerMessage struct {
 	Type    string      `json:"type"`
@@ -41,30 +21,114 @@ type ServerMessage struct {
 
 // Message types from server
 const (
-	MsgTypeGameState    = "game_state"    // Full game state update
-	MsgTypePlayerJoined = "player_joined" // A player joined
-	MsgTypePlayerLeft   = "player_left"   // A player left
-	MsgTypeError        = "error"         // Error message
-	MsgTypeConnected    = "connected"     // Connection confirmed
-	MsgTypeLobbyInfo    = "lobby_info"    // Lobby information
+	MsgTypeGameState       = "game_state"       // Full game state update
+	MsgTypePlayerJoined    = "player_joined"    // A player joined
+	MsgTypePlayerLeft      = "player_left"      // A player left
+	MsgTypeError           = "error"            // Error message
+	MsgTypeConnected       = "connected"        // Connection confirmed
+	MsgTypeLobbyInfo       = "lobby_info"       // Lobby information
+	MsgTypeStateSync       = "state_sync"       // Full snapshot replayed to a resumed session
+	MsgTypeKicked          = "kicked"           // Inactivity warning before eviction
+	MsgTypePlayerKicked    = "player_kicked"    // Announces a player was evicted
+	MsgTypeReplayDone      = "replay_done"      // Playback lobby finished replaying its recording
+	MsgTypeSpectatorJoined = "spectator_joined" // Announces a spectator joined the lobby
+	MsgTypeMapInit         = "map_init"         // One-time arena layout sent to a client on join
+	MsgTypeProbeResult     = "probe_result"     // Answers a probe raycast query
 )
 
-// ConnectedPayload is sent when a player successfully connects
+// MsgTypeProbe is a client message carrying a ProbePayload. It's handled
+// directly by Client.handleMessage rather than through game.EffectRegistry:
+// a probe is a read-only raycast query answered with a ProbeResultPayload,
+// not a mutation applied on the game's next tick.
+const MsgTypeProbe = "probe"
+
+// ConnectedPayload is sent when a player successfully connects. TankIDs
+// lists the player's fleet, in spawn order; it's empty for a spectator,
+// who controls no tanks.
 type ConnectedPayload struct {
-	PlayerID  string `json:"playerId"`
-	LobbyCode string `json:"lobbyCode"`
+	PlayerID     string   `json:"playerId"`
+	LobbyCode    string   `json:"lobbyCode"`
+	SessionToken string   `json:"sessionToken"`
+	Resumed      bool     `json:"resumed"`
+	TankIDs      []string `json:"tankIds,omitempty"`
+}
+
+// GameStatePayload carries the match state visible to the receiving
+// client. Turn is the server's monotonically increasing tick counter, so a
+// client can detect a dropped frame (a gap in Turn) and interpolate its
+// render further to catch up rather than assuming every update arrived
+// back-to-back. Players holds full detail for tanks the client controls
+// (or, when sent to a spectator, every tank); Enemies holds the
+// fog-of-war truncated view of every other tank within scanner range and
+// line of sight. Enemies is omitted where empty; Players is always sent.
+type GameStatePayload struct {
+	Turn      uint64                             `json:"turn"`
+	State     game.GameState                     `json:"state"`
+	Players   map[string]game.TankState          `json:"players"`
+	Enemies   map[string]game.TruncatedTankState `json:"enemies,omitempty"`
+	Bullets   []game.BulletState                 `json:"bullets"`
+	Splosions []game.SplosionState               `json:"splosions,omitempty"`
 }
 
 // LobbyInfoPayload contains lobby state
 type LobbyInfoPayload struct {
-	Code        string   `json:"code"`
-	PlayerCount int      `json:"playerCount"`
-	MaxPlayers  int      `json:"maxPlayers"`
-	State       string   `json:"state"`
-	Players     []string `json:"players"`
+	Code           string   `json:"code"`
+	PlayerCount    int      `json:"playerCount"`
+	MaxPlayers     int      `json:"maxPlayers"`
+	SpectatorCount int      `json:"spectatorCount"`
+	State          string   `json:"state"`
+	Players        []string `json:"players"`
+	TanksPerPlayer int      `json:"tanksPerPlayer"`
+	Mode           string   `json:"mode"`
 }
 
 // ErrorPayload contains error information
 type ErrorPayload struct {
 	Message string `json:"message"`
 }
+
+// KickWarningPayload warns an idle player they're about to be evicted
+type KickWarningPayload struct {
+	Message          string  `json:"message"`
+	SecondsRemaining float64 `json:"secondsRemaining"`
+}
+
+// PlayerKickedPayload announces that a player was evicted from the match
+type PlayerKickedPayload struct {
+	PlayerID string `json:"playerId"`
+	Reason   string `json:"reason"`
+}
+
+// ReplayDonePayload announces that a playback lobby reached the end of its
+// recording; spectators should expect no further game_state updates.
+type ReplayDonePayload struct {
+	LobbyCode string `json:"lobbyCode"`
+}
+
+// SpectatorJoinedPayload announces that a spectator joined a live lobby.
+type SpectatorJoinedPayload struct {
+	SpectatorID string `json:"spectatorId"`
+}
+
+// MapInitPayload describes the arena a client just joined: its seed (for
+// clients that want to regenerate the layout locally) and the authoritative
+// obstacle list. It's generated once per lobby and sent on join, never
+// re-sent, since the arena never changes after construction.
+type MapInitPayload struct {
+	Seed      int64           `json:"seed"`
+	Obstacles []game.Obstacle `json:"obstacles"`
+}
+
+// ProbePayload asks the server to raycast from the caller's selected
+// tank's turret origin toward Probe, for client-side aim assist or
+// scripted/AI tanks predicting a shot before committing to fire it.
+type ProbePayload struct {
+	Probe game.Vector2 `json:"probe"`
+}
+
+// ProbeResultPayload answers a MsgTypeProbe query. Hit is nil if the ray
+// reached the requested point unobstructed, otherwise the nearest
+// obstacle or tank it crossed first.
+type ProbeResultPayload struct {
+	Hit *game.Collision `json:"hit"`
+}
@@ -0,0 +1,151 @@
+// Package replay records deterministic matches as a stream of framed JSON
+// events and plays them back by re-simulating the recorded game with the
+// same inputs. It has no knowledge of lobbies or websockets; network wires
+// it into Lobby.
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"tankio/config"
+	"tankio/game"
+)
+
+// EventType identifies the kind of frame recorded in a replay stream.
+type EventType string
+
+const (
+	// EventStart is always the first frame: the match's starting conditions.
+	EventStart EventType = "start"
+	// EventInput is one Effect as it was submitted to Game.Enqueue.
+	EventInput EventType = "input"
+	// EventSnapshot is one broadcast state snapshot.
+	EventSnapshot EventType = "snapshot"
+)
+
+// Frame is one line of a replay stream: a typed, tick-stamped event.
+type Frame struct {
+	Type EventType       `json:"type"`
+	Tick uint64          `json:"tick"`
+	Data json.RawMessage `json:"data"`
+}
+
+// StartFrame carries everything needed to reconstruct a match's starting
+// conditions deterministically: the config it ran under, the seed its
+// bullet ID generator started from, its tick rate, and the order players
+// joined in (spawn points are assigned by join order, so replaying that
+// order reproduces the original spawn assignments).
+type StartFrame struct {
+	Config      config.Config `json:"config"`
+	Seed        int64         `json:"seed"`
+	TickMs      int           `json:"tickMs"`
+	PlayerOrder []string      `json:"playerOrder"`
+	StartedAt   time.Time     `json:"startedAt"`
+}
+
+// InputFrame carries one Effect exactly as it was submitted to Game.Enqueue:
+// its player, its wire Kind (an EffectRegistry key), and its raw JSON
+// payload, so playback can reconstruct the concrete Effect without knowing
+// about it in advance.
+type InputFrame struct {
+	PlayerID string          `json:"playerId"`
+	Kind     string          `json:"kind"`
+	Payload  json.RawMessage `json:"payload"`
+}
+
+// SnapshotFrame carries one broadcast state snapshot.
+type SnapshotFrame struct {
+	Snapshot game.Snapshot `json:"snapshot"`
+}
+
+// Recorder appends framed events to an in-memory buffer and, if created
+// with a directory, to a newline-delimited JSON file under it (one frame
+// per line), so playback is a straight re-feed.
+type Recorder struct {
+	mu     sync.Mutex
+	frames []Frame
+	file   *os.File
+}
+
+// NewRecorder creates a recorder for lobbyCode starting at startedAt. If dir
+// is empty, frames are kept in memory only (no file is written).
+func NewRecorder(dir, lobbyCode string, startedAt time.Time) (*Recorder, error) {
+	r := &Recorder{}
+	if dir == "" {
+		return r, nil
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("replay: create dir %q: %w", dir, err)
+	}
+
+	name := fmt.Sprintf("%s-%d.jsonl", lobbyCode, startedAt.UnixNano())
+	f, err := os.Create(filepath.Join(dir, name))
+	if err != nil {
+		return nil, fmt.Errorf("replay: create file: %w", err)
+	}
+	r.file = f
+	return r, nil
+}
+
+// RecordStart appends the match's starting conditions. Should be the first
+// frame recorded, at tick 0.
+func (r *Recorder) RecordStart(start StartFrame) {
+	r.record(EventStart, 0, start)
+}
+
+// RecordInput appends one player's effect at the given tick. payload is the
+// effect's own JSON encoding (see Effect.Kind), exactly as it will be
+// re-decoded via game.EffectRegistry during playback.
+func (r *Recorder) RecordInput(tick uint64, playerID, kind string, payload json.RawMessage) {
+	r.record(EventInput, tick, InputFrame{PlayerID: playerID, Kind: kind, Payload: payload})
+}
+
+// RecordSnapshot appends a broadcast state snapshot at the given tick.
+func (r *Recorder) RecordSnapshot(tick uint64, snap game.Snapshot) {
+	r.record(EventSnapshot, tick, SnapshotFrame{Snapshot: snap})
+}
+
+func (r *Recorder) record(t EventType, tick uint64, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	frame := Frame{Type: t, Tick: tick, Data: data}
+
+	r.mu.Lock()
+	r.frames = append(r.frames, frame)
+	f := r.file
+	r.mu.Unlock()
+
+	if f == nil {
+		return
+	}
+	line, err := json.Marshal(frame)
+	if err != nil {
+		return
+	}
+	f.Write(append(line, '\n'))
+}
+
+// Frames returns a copy of every frame recorded so far.
+func (r *Recorder) Frames() []Frame {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]Frame, len(r.frames))
+	copy(out, r.frames)
+	return out
+}
+
+// Close flushes and closes the backing file, if any.
+func (r *Recorder) Close() error {
+	if r.file == nil {
+		return nil
+	}
+	return r.file.Close()
+}
@@ -0,0 +1,74 @@
+package replay
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Recording is a finished match's replay stream, kept in memory so it can
+// be served and replayed without re-reading its backing file.
+type Recording struct {
+	ID        string
+	LobbyCode string
+	StartedAt time.Time
+	Frames    []Frame
+}
+
+// Store indexes finished recordings by ID for the replay HTTP endpoints.
+type Store struct {
+	mu         sync.RWMutex
+	dir        string
+	recordings map[string]*Recording
+	order      []string
+}
+
+// NewStore creates a replay store. dir is where recorders persist their
+// frame files; it's surfaced back to callers via Dir so they can pass it
+// into NewRecorder. An empty dir means recordings are kept in memory only.
+func NewStore(dir string) *Store {
+	return &Store{
+		dir:        dir,
+		recordings: make(map[string]*Recording),
+	}
+}
+
+// Dir returns the directory replay files are written under.
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Add indexes a finished recording under a new ID and returns it.
+func (s *Store) Add(lobbyCode string, startedAt time.Time, frames []Frame) *Recording {
+	rec := &Recording{
+		ID:        fmt.Sprintf("%s-%d", lobbyCode, startedAt.UnixNano()),
+		LobbyCode: lobbyCode,
+		StartedAt: startedAt,
+		Frames:    frames,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.recordings[rec.ID] = rec
+	s.order = append(s.order, rec.ID)
+	return rec
+}
+
+// Get returns a recording by ID.
+func (s *Store) Get(id string) (*Recording, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rec, ok := s.recordings[id]
+	return rec, ok
+}
+
+// List returns every recording, oldest first.
+func (s *Store) List() []*Recording {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]*Recording, 0, len(s.order))
+	for _, id := range s.order {
+		out = append(out, s.recordings[id])
+	}
+	return out
+}